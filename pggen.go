@@ -10,6 +10,7 @@ import (
 	"pggen/pgsql"
 	"strings"
 	"text/template"
+	"time"
 )
 
 type args struct {
@@ -19,12 +20,16 @@ type args struct {
 	OutputPath       string
 	ConnectionString string
 	PackageRoot      string
+	TypeOverrides    map[string]string
+	Driver           pgsql.Driver
 }
 
 func help() {
-	fmt.Println("\npggen <[-v path_to_vault -k vault_key -f encrypted_filename] | [-c connection_string]> [-o outputPath] [-p packageRoot] ")
+	fmt.Println("\npggen <[-v path_to_vault -k vault_key -f encrypted_filename] | [-c connection_string]> [-o outputPath] [-p packageRoot] [-t domain=gotype ...] [-d pq|pgx]")
 	fmt.Println("If a connection string to a pgsql db is not included (-c option) then the -v -k -f combination is expected")
 	fmt.Println("where encrypted_filename is a vault file containing a encrypted connection string.")
+	fmt.Println("-t overrides the go type used for columns of a domain, e.g. -t public.email=github.com/x/mail.Address")
+	fmt.Println("-d selects the driver the generated code runs on, database/sql with lib/pq (pq, the default) or pgx/v5 (pgx)")
 	fmt.Println("\npggen -h")
 	fmt.Println("Prints this help message and exits the program.")
 
@@ -45,7 +50,7 @@ func parseArgs() (args, bool) {
 		os.Exit(-1)
 	}
 
-	a := args{}
+	a := args{TypeOverrides: map[string]string{}, Driver: pgsql.DriverPQ}
 	oa := os.Args[1:]
 
 	for i := 0; i < len(oa); i++ {
@@ -65,6 +70,22 @@ func parseArgs() (args, bool) {
 			a.ConnectionString = nextArg(oa, i, "arguments -c (connection string expected)")
 		case "-p":
 			a.PackageRoot = nextArg(oa, i, "arguments -p (packageRoot string expected)")
+		case "-t":
+			override := nextArg(oa, i, "arguments -t (domain=gotype expected)")
+			i++
+			kv := strings.SplitN(override, "=", 2)
+			if len(kv) != 2 || len(kv[0]) == 0 || len(kv[1]) == 0 {
+				help()
+				panic("pggen: arguments -t (domain=gotype expected)")
+			}
+			a.TypeOverrides[kv[0]] = kv[1]
+		case "-d":
+			a.Driver = pgsql.Driver(nextArg(oa, i, "arguments -d (pq or pgx expected)"))
+			i++
+			if a.Driver != pgsql.DriverPQ && a.Driver != pgsql.DriverPgx {
+				help()
+				panic("pggen: arguments -d (pq or pgx expected)")
+			}
 		case "-h":
 			help()
 			os.Exit(-1)
@@ -101,7 +122,7 @@ func main() {
 
 	fmt.Printf("Loaded connection string, %s\n", connectionStr)
 
-	pg, err := pgsql.NewPgSQL(connectionStr)
+	pg, err := pgsql.NewPgSQL(connectionStr, pgsql.WithApplicationName("pggen"), pgsql.WithStartupCheck(30*time.Second))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "PgSQL error during setup: %s\n", err)
 		return
@@ -114,8 +135,23 @@ func main() {
 		return
 	}
 
+	postgis, err := pg.HasExtension("postgis")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to check for the postgis extension: %s\n", err)
+		return
+	}
+
+	for _, table := range tables {
+		for _, warning := range pgsql.ResolveTable(table) {
+			fmt.Fprintf(os.Stderr, "Warning %s.%s: %s\n", table.Schema, table.Name, warning)
+		}
+	}
+
 	for _, table := range tables {
 		fmt.Printf("%s.%s\n", table.Schema, table.Name)
+		if len(table.Doc) > 0 {
+			fmt.Printf("\t-- %s\n", strings.Replace(table.Doc, "\n", " ", -1))
+		}
 
 		columns, err := pg.GetColumns(table)
 		if err != nil {
@@ -138,6 +174,11 @@ func main() {
 
 			}
 
+			if len(column.Comment) > 0 {
+				doc, _, _ := pgsql.ParseAnnotations(column.Comment)
+				fmt.Printf("\t-- %s", strings.Replace(doc, "\n", " ", -1))
+			}
+
 			fmt.Println()
 		}
 
@@ -164,12 +205,91 @@ func main() {
 		"returnKeyClause":        pgsql.ReturnKeyClause,
 		"primaryKeyFunctionArgs": pgsql.PrimaryKeyFunctionArgs,
 		"createTestStruct":       pgsql.CreateTestStruct,
+		"testColumns":            pgsql.TestColumns,
+		"comment":                comment,
+		"scanArg":                pgsql.ScanArg,
+		"bindArg":                pgsql.BindArg,
+		"patchArg":               pgsql.PatchArg,
 		"inc": func(i int) int {
 			return i + 1
 		},
+		"add": func(a, b int) int {
+			return a + b
+		},
+	}
+
+	domainList, err := pg.GetDomains()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to get domains: %s\n", err)
+		return
+	}
+
+	domains := map[string]*pgsql.Domain{}
+
+	for _, d := range domainList {
+		resolver := &pgsql.Resolver{Schema: d.Schema, PackageRoot: args.PackageRoot, Overrides: args.TypeOverrides}
+		warnings, err := resolver.ResolveDomain(d)
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "Warning %s.%s: %s\n", d.Schema, d.Name, warning)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to get type for domain %s.%s: %s\n", d.Schema, d.Name, err)
+			return
+		}
+
+		domains[d.Schema+"."+d.Name] = d
+	}
+
+	compositeTypes, err := pg.GetCompositeTypes()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to get composite types: %s\n", err)
+		return
+	}
+
+	schemas := []string{}
+	schemaTypes := map[string][]*pgsql.CompositeType{}
+	schemaDomains := map[string][]*pgsql.Domain{}
+
+	for _, d := range domainList {
+		if len(d.GoName) == 0 {
+			continue
+		}
+
+		if _, ok := schemaTypes[d.Schema]; !ok {
+			schemas = append(schemas, d.Schema)
+			schemaTypes[d.Schema] = []*pgsql.CompositeType{}
+		}
+
+		schemaDomains[d.Schema] = append(schemaDomains[d.Schema], d)
+	}
+
+	for _, ct := range compositeTypes {
+		resolver := &pgsql.Resolver{Schema: ct.Schema, PackageRoot: args.PackageRoot, Domains: domains, Overrides: args.TypeOverrides}
+		warnings, err := resolver.ResolveCompositeType(ct)
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "Warning %s.%s: %s\n", ct.Schema, ct.Name, warning)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to get type for composite %s.%s: %s\n", ct.Schema, ct.Name, err)
+			return
+		}
+
+		if ct.Annotations.Skip {
+			continue
+		}
+
+		if _, ok := schemaTypes[ct.Schema]; !ok {
+			schemas = append(schemas, ct.Schema)
+		}
+
+		schemaTypes[ct.Schema] = append(schemaTypes[ct.Schema], ct)
 	}
 
 	for _, table := range tables {
+		if table.Annotations.Skip {
+			continue
+		}
+
 		dir := filepath.Join(args.OutputPath, table.Schema)
 		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
 			fmt.Fprintf(os.Stderr, "Unable to create dir %s : %s\n", dir, err)
@@ -207,27 +327,38 @@ func main() {
 		imp := []string{
 			"pggen/pgsql",
 			"fmt",
-			"reflect",
-			"time",
+			"context",
+			"iter",
 		}
 
 		dat := struct {
-			Schema             string
-			Name               string
-			Columns            []*pgsql.Column
-			Imports            []string
-			TestImports        []string
-			Constraints        []*pgsql.TableConstraints
-			ConnectionString   string
-			PackageRoot        string
-			PrimaryKeyNames    []string
-			NonPrimaryKeyNames []string
+			Schema           string
+			Name             string
+			Doc              string
+			Columns          []*pgsql.Column
+			Imports          []string
+			TestImports      []string
+			Constraints      []*pgsql.TableConstraints
+			ConnectionString string
+			PackageRoot      string
+			PrimaryKeys      []*pgsql.Column
+			NonPrimaryKeys   []*pgsql.Column
+			UpdateColumns    []*pgsql.Column
+			RangeColumns     []*pgsql.RangeColumn
+			NetworkColumns   []*pgsql.Column
+			SpatialColumns   []*pgsql.Column
+			SearchColumns    []*pgsql.SearchColumn
+			Version          *pgsql.VersionColumn
+			Redacted         bool
+			Pgx              bool
 		}{
 			Schema:           table.Schema,
 			Name:             table.Name,
+			Doc:              table.Doc,
 			Imports:          imp,
 			PackageRoot:      args.PackageRoot,
 			ConnectionString: connectionStr,
+			Pgx:              args.Driver == pgsql.DriverPgx,
 		}
 
 		columns, err := pg.GetColumns(table)
@@ -236,41 +367,39 @@ func main() {
 			return
 		}
 
+		resolver := &pgsql.Resolver{Schema: table.Schema, PackageRoot: args.PackageRoot, Domains: domains, Overrides: args.TypeOverrides}
+
 		for _, column := range columns {
-			gotype, err := pgsql.ToGo(column.Type)
+			warnings, err := resolver.ResolveColumn(column)
+			for _, warning := range warnings {
+				fmt.Fprintf(os.Stderr, "Warning %s.%s.%s: %s\n", table.Schema, table.Name, column.Name, warning)
+			}
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "Unable to get type for %s: %s\n", column.Type, err)
 				return
 			}
-			dat.Columns = append(dat.Columns, column)
-			if gotype == "time.Time" {
-				contains := false
-
-				for _, val := range dat.Imports {
-					if val == "time" {
-						contains = true
-						break
-					}
-				}
 
-				if !contains {
-					dat.Imports = append(dat.Imports, "time")
-				}
+			if column.Annotations.Skip {
+				continue
+			}
 
-				contains = false
-				for _, val := range dat.TestImports {
-					if val == "time" {
-						contains = true
-						break
-					}
-				}
+			dat.Columns = append(dat.Columns, column)
+			dat.Redacted = dat.Redacted || column.Annotations.Redact
 
-				if !contains {
-					dat.TestImports = append(dat.TestImports, "time")
-				}
+			if usesTime(column.GoType) {
+				dat.Imports = addImport(dat.Imports, "time")
+			}
+
+			if len(column.Import) > 0 {
+				dat.Imports = addImport(dat.Imports, column.Import)
 			}
+		}
 
+		version, columns, warnings := pgsql.TableVersion(table, dat.Columns)
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "Warning %s.%s: %s\n", table.Schema, table.Name, warning)
 		}
+		dat.Version, dat.Columns = version, columns
 
 		tableConstraints, err := pg.GetTableConstraints(table)
 		if err != nil {
@@ -279,9 +408,31 @@ func main() {
 		}
 
 		dat.Constraints = tableConstraints
+		// the test values of inet and cidr columns are built from netip values
+		candidates := addImport(dat.Imports[:len(dat.Imports):len(dat.Imports)], "net/netip")
+		dat.TestImports = testImports(candidates, pgsql.CreateTestStruct(dat.Columns, tableConstraints))
+		for _, column := range pgsql.UntestedColumns(dat.Columns, tableConstraints) {
+			fmt.Fprintf(os.Stderr, "Warning %s.%s.%s: no test value for %s, the generated Create test leaves the NOT NULL column out and fails\n", table.Schema, table.Name, column.Name, column.GoType)
+		}
+
+		dat.PrimaryKeys = pgsql.PrimaryKeyColumns(dat.Columns, tableConstraints)
+		dat.NonPrimaryKeys = pgsql.NonPrimaryKeyColumns(dat.Columns, tableConstraints)
+		dat.UpdateColumns = pgsql.UpdateColumns(dat.NonPrimaryKeys, dat.Version)
+		dat.RangeColumns = pgsql.RangeColumns(dat.Columns)
+		dat.NetworkColumns = pgsql.NetworkColumns(dat.Columns)
+		if len(dat.NetworkColumns) > 0 {
+			// the network filter helpers take netip values
+			dat.Imports = addImport(dat.Imports, "net/netip")
+		}
+		if postgis {
+			dat.SpatialColumns = pgsql.SpatialColumns(dat.Columns)
+		}
 
-		dat.PrimaryKeyNames = pgsql.PrimaryKeyNames(columns, tableConstraints)
-		dat.NonPrimaryKeyNames = pgsql.NonPrimaryKeyNames(columns, tableConstraints)
+		searchColumns, warnings := pgsql.SearchColumns(dat.Columns)
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "Warning %s.%s: %s\n", table.Schema, table.Name, warning)
+		}
+		dat.SearchColumns = searchColumns
 
 		if err := tableTmpl.Execute(file, dat); err != nil {
 			log.Fatal(err)
@@ -293,6 +444,85 @@ func main() {
 
 	}
 
+	typesTmpl, err := ioutil.ReadFile("templates/types.tmpl")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to read templates/types.tmpl: %s\n", err)
+		return
+	}
+
+	for _, schema := range schemas {
+		dir := filepath.Join(args.OutputPath, schema)
+		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to create dir %s : %s\n", dir, err)
+			os.Exit(-1)
+		}
+
+		filename := filepath.Join(dir, "types.go")
+		file, err := os.Create(filename)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error opening file %s : %s\n", filename, err)
+			os.Exit(-1)
+		}
+
+		defer file.Close()
+
+		tmpl, err := template.New("Types").Funcs(funcs).Parse(string(typesTmpl))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		dat := struct {
+			Schema         string
+			Imports        []string
+			CompositeTypes []*pgsql.CompositeType
+			Domains        []*pgsql.Domain
+		}{
+			Schema:         schema,
+			CompositeTypes: schemaTypes[schema],
+			Domains:        schemaDomains[schema],
+		}
+
+		if len(dat.CompositeTypes) > 0 {
+			dat.Imports = []string{"database/sql/driver", "pggen/pgsql"}
+		}
+
+		for _, d := range dat.Domains {
+			if len(d.Base.Import) > 0 {
+				dat.Imports = addImport(dat.Imports, d.Base.Import)
+			}
+
+			if len(d.Patterns) > 0 {
+				dat.Imports = addImport(dat.Imports, "regexp")
+			}
+
+			for _, check := range d.Checks {
+				if len(check.GoExpr) > 0 {
+					dat.Imports = addImport(dat.Imports, "errors")
+				}
+
+				if strings.Contains(check.GoExpr, "utf8.") {
+					dat.Imports = addImport(dat.Imports, "unicode/utf8")
+				}
+			}
+		}
+
+		for _, ct := range dat.CompositeTypes {
+			for _, a := range ct.Attributes {
+				if usesTime(a.GoType) {
+					dat.Imports = addImport(dat.Imports, "time")
+				}
+
+				if len(a.Import) > 0 {
+					dat.Imports = addImport(dat.Imports, a.Import)
+				}
+			}
+		}
+
+		if err := tmpl.Execute(file, dat); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	fmt.Println("\nDone.")
 }
 
@@ -308,12 +538,55 @@ func getColumnConstraints(tableConstraints []*pgsql.TableConstraints, columnName
 	return tc
 }
 
+// addImport appends path to imports unless it is already present
+func addImport(imports []string, path string) []string {
+	for _, val := range imports {
+		if val == path {
+			return imports
+		}
+	}
+
+	return append(imports, path)
+}
+
+// testImports returns the imports whose package is referenced by the values in testStruct.
+// pggen/pgsql is always imported by the test template
+func testImports(imports []string, testStruct string) []string {
+	used := []string{}
+
+	for _, path := range imports {
+		pkg := path[strings.LastIndex(path, "/")+1:]
+		if path != "pggen/pgsql" && strings.Contains(testStruct, pkg+".") {
+			used = append(used, path)
+		}
+	}
+
+	return used
+}
+
+// usesTime reports whether a go type, or its element or range bound type, is time.Time
+func usesTime(goType string) bool {
+	i := strings.Index(goType, "time.Time")
+
+	return i == 0 || i > 0 && strings.ContainsAny(goType[i-1:i], "[]*")
+}
+
 func togo(t string) string {
 	tp, _ := pgsql.ToGo(t)
 
 	return tp
 }
 
+// comment formats text as a go line comment, each line prefixed with indent
+func comment(indent string, text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(indent+"// "+strings.TrimSpace(line), " ")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
 func onlyOne(a []string) bool {
 	return len(a) == 1
 }
@@ -0,0 +1,114 @@
+package pgsql
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// annotationPrefix marks a generation hint inside the text of a COMMENT ON statement
+const annotationPrefix = "@pggen:"
+
+// searchConfigName matches the text search configuration names accepted by @pggen:tsconfig
+var searchConfigName = regexp.MustCompile(`^[a-z_][a-z0-9_]*(\.[a-z_][a-z0-9_]*)?$`)
+
+// Annotations holds the generation hints found in a table or column comment
+// e.g. COMMENT ON COLUMN member.id IS 'Member identifier @pggen:name=MemberID'
+type Annotations struct {
+	// Skip (@pggen:skip) leaves the column or table out of the generated code. A skipped attribute
+	// of a composite type is kept as unexported text so that Value writes it back unchanged
+	Skip bool
+	// Type (@pggen:type=github.com/x/y.Money) replaces the go type derived from the sql type
+	Type string
+	// Name (@pggen:name=MemberID) replaces the go field name derived from the column name
+	Name string
+	// Redact (@pggen:redact) masks the column value when the generated struct is printed
+	Redact bool
+	// JSON (@pggen:json=github.com/x/y.Settings) decodes a json or jsonb column into pgsql.JSON[y.Settings]
+	JSON string
+	// Headline (@pggen:headline=body) names the text column a tsvector column is built from,
+	// the generated Search method returns ts_headline snippets of it
+	Headline string
+	// TSConfig (@pggen:tsconfig=english) is the text search configuration the generated Search
+	// method parses queries with, the database default_text_search_config when empty
+	TSConfig string
+	// Version (@pggen:version) marks an integer column as the version of its row, compared and
+	// incremented by the generated Update, Patch and Delete methods
+	Version bool
+	// VersionColumn (@pggen:version=xmin) names the version column of a table, either one of its
+	// integer columns or the xmin system column, which postgres advances on every update
+	VersionColumn string
+}
+
+// ParseAnnotations splits a database comment into its descriptive text and the
+// @pggen markers it contains. Unknown or malformed markers are ignored and
+// reported in the returned warnings.
+func ParseAnnotations(comment string) (string, Annotations, []string) {
+	var a Annotations
+	var warnings []string
+	var lines []string
+
+	for _, line := range strings.Split(comment, "\n") {
+		words := []string{}
+
+		for _, word := range strings.Fields(line) {
+			if !strings.HasPrefix(word, annotationPrefix) {
+				words = append(words, word)
+				continue
+			}
+
+			key := strings.TrimPrefix(word, annotationPrefix)
+			value := ""
+			hasValue := false
+			if i := strings.Index(key, "="); i >= 0 {
+				key, value, hasValue = key[:i], key[i+1:], true
+			}
+
+			switch {
+			case key == "skip" && !hasValue:
+				a.Skip = true
+			case key == "redact" && !hasValue:
+				a.Redact = true
+			case key == "type" && len(value) > 0:
+				a.Type = value
+			case key == "name" && len(value) > 0:
+				a.Name = value
+			case key == "json" && len(value) > 0:
+				a.JSON = value
+			case key == "headline" && len(value) > 0:
+				a.Headline = value
+			case key == "tsconfig" && searchConfigName.MatchString(value):
+				a.TSConfig = value
+			case key == "version" && !hasValue:
+				a.Version = true
+			case key == "version" && len(value) > 0:
+				a.VersionColumn = value
+			default:
+				warnings = append(warnings, fmt.Sprintf("unknown annotation %s", word))
+			}
+		}
+
+		if len(words) > 0 {
+			lines = append(lines, strings.Join(words, " "))
+		}
+	}
+
+	return strings.Join(lines, "\n"), a, warnings
+}
+
+// QualifiedType splits a type annotation such as github.com/x/y.Money or []*github.com/x/y.Money
+// into the go type used in generated code (y.Money, []*y.Money) and the import path it needs
+func QualifiedType(typeStr string) (string, string) {
+	prefix := typeStr[:len(typeStr)-len(strings.TrimLeft(typeStr, "[]*"))]
+	name := typeStr[len(prefix):]
+
+	dot := strings.LastIndex(name, ".")
+	if dot < 0 {
+		return typeStr, ""
+	}
+
+	importPath := name[:dot]
+	pkg := importPath[strings.LastIndex(importPath, "/")+1:]
+
+	return prefix + pkg + name[dot:], importPath
+}
@@ -0,0 +1,58 @@
+package pgsql_test
+
+import (
+	"reflect"
+	"testing"
+
+	"pggen/pgsql"
+)
+
+func TestParseAnnotations(t *testing.T) {
+	tests := []struct {
+		comment     string
+		doc         string
+		annotations pgsql.Annotations
+		warnings    int
+	}{
+		{"", "", pgsql.Annotations{}, 0},
+		{"Member identifier @pggen:name=MemberID", "Member identifier", pgsql.Annotations{Name: "MemberID"}, 0},
+		{"@pggen:skip", "", pgsql.Annotations{Skip: true}, 0},
+		{"@pggen:redact\nthe secret", "the secret", pgsql.Annotations{Redact: true}, 0},
+		{"Price @pggen:type=github.com/x/money.Money in cents", "Price in cents", pgsql.Annotations{Type: "github.com/x/money.Money"}, 0},
+		{"@pggen:json=github.com/x/y.Settings", "", pgsql.Annotations{JSON: "github.com/x/y.Settings"}, 0},
+		{"@pggen:headline=notes @pggen:tsconfig=english", "", pgsql.Annotations{Headline: "notes", TSConfig: "english"}, 0},
+		{"@pggen:version", "", pgsql.Annotations{Version: true}, 0},
+		{"@pggen:version=xmin", "", pgsql.Annotations{VersionColumn: "xmin"}, 0},
+		{"first line\n\nsecond line", "first line\nsecond line", pgsql.Annotations{}, 0},
+		{"@pggen:bogus @pggen:skip=yes @pggen:name= @pggen:type", "", pgsql.Annotations{}, 4},
+		{"@pggen:tsconfig=english');drop", "", pgsql.Annotations{}, 1},
+	}
+
+	for _, tt := range tests {
+		doc, a, warnings := pgsql.ParseAnnotations(tt.comment)
+		if doc != tt.doc || !reflect.DeepEqual(a, tt.annotations) || len(warnings) != tt.warnings {
+			t.Errorf("ParseAnnotations(%q) = %q, %+v, %v, expected %q, %+v and %d warnings", tt.comment, doc, a, warnings, tt.doc, tt.annotations, tt.warnings)
+		}
+	}
+}
+
+func TestQualifiedType(t *testing.T) {
+	tests := []struct {
+		typeStr    string
+		goType     string
+		importPath string
+	}{
+		{"int64", "int64", ""},
+		{"[]*string", "[]*string", ""},
+		{"github.com/x/money.Money", "money.Money", "github.com/x/money"},
+		{"[]*github.com/x/money.Money", "[]*money.Money", "github.com/x/money"},
+		{"[][]github.com/x/y.Point", "[][]y.Point", "github.com/x/y"},
+		{"time.Duration", "time.Duration", "time"},
+	}
+
+	for _, tt := range tests {
+		if goType, importPath := pgsql.QualifiedType(tt.typeStr); goType != tt.goType || importPath != tt.importPath {
+			t.Errorf("QualifiedType(%q) = %q, %q, expected %q, %q", tt.typeStr, goType, importPath, tt.goType, tt.importPath)
+		}
+	}
+}
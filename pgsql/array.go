@@ -0,0 +1,207 @@
+package pgsql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ArrayCodec scans and binds go slices using the postgres array text format
+type ArrayCodec struct {
+	a interface{}
+}
+
+// Array wraps a pointer to a slice for use with row.Scan, or a slice for use as a query argument.
+// Nested slices map to multi-dimensional arrays. NULL elements are stored as nil in slices of
+// pointers and as the zero value otherwise. Elements may implement sql.Scanner and driver.Valuer
+func Array(a interface{}) *ArrayCodec {
+	return &ArrayCodec{a: a}
+}
+
+// Scan implements sql.Scanner
+func (ac *ArrayCodec) Scan(src interface{}) error {
+	v := reflect.ValueOf(ac.a)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("pgsql: Array scan destination must be a pointer to a slice, not %T", ac.a)
+	}
+
+	if src == nil {
+		v.Elem().Set(reflect.Zero(v.Elem().Type()))
+		return nil
+	}
+
+	text, err := srcText(src)
+	if err != nil {
+		return err
+	}
+
+	node, err := parseArray(text)
+	if err != nil {
+		return err
+	}
+
+	return assignArray(v.Elem(), node)
+}
+
+// Value implements driver.Valuer
+func (ac *ArrayCodec) Value() (driver.Value, error) {
+	v := reflect.ValueOf(ac.a)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("pgsql: Array value must be a slice, not %T", ac.a)
+	}
+
+	if v.IsNil() {
+		return nil, nil
+	}
+
+	return formatArray(v)
+}
+
+var _ sql.Scanner = (*ArrayCodec)(nil)
+var _ driver.Valuer = (*ArrayCodec)(nil)
+
+// arrayNode is an element of a parsed array literal, either a nested array or a value
+type arrayNode struct {
+	elems   []*arrayNode
+	isArray bool
+	text    string
+	null    bool
+}
+
+// parseArray parses an array literal such as {1,2,NULL} or [0:1]={{"a","b"},{"c",d}}
+func parseArray(text string) (*arrayNode, error) {
+	if strings.HasPrefix(text, "[") {
+		i := strings.Index(text, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("pgsql: invalid array dimensions in %q", text)
+		}
+		text = text[i+1:]
+	}
+
+	p := &literalParser{text: text}
+	node, err := p.array()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos != len(p.text) {
+		return nil, fmt.Errorf("pgsql: unexpected %q after array literal", p.text[p.pos:])
+	}
+
+	return node, nil
+}
+
+func (p *literalParser) array() (*arrayNode, error) {
+	if p.peek() != '{' {
+		return nil, fmt.Errorf("pgsql: expected '{' at %d in %q", p.pos, p.text)
+	}
+	p.pos++
+
+	node := &arrayNode{isArray: true}
+	if p.peek() == '}' {
+		p.pos++
+		return node, nil
+	}
+
+	for {
+		var elem *arrayNode
+		if p.peek() == '{' {
+			sub, err := p.array()
+			if err != nil {
+				return nil, err
+			}
+			elem = sub
+		} else {
+			text, quoted, err := p.value(",}")
+			if err != nil {
+				return nil, err
+			}
+			elem = &arrayNode{text: text, null: !quoted && strings.EqualFold(text, "NULL")}
+		}
+		node.elems = append(node.elems, elem)
+
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return node, nil
+		default:
+			return nil, fmt.Errorf("pgsql: unterminated array literal %q", p.text)
+		}
+	}
+}
+
+// assignArray stores a parsed array in the slice v, allocating nested slices for each dimension
+func assignArray(v reflect.Value, node *arrayNode) error {
+	if !node.isArray {
+		return errors.New("pgsql: expected an array")
+	}
+
+	s := reflect.MakeSlice(v.Type(), len(node.elems), len(node.elems))
+	for i, elem := range node.elems {
+		ev := s.Index(i)
+
+		if elem.isArray {
+			if ev.Kind() != reflect.Slice || ev.Type() == bytesType {
+				return fmt.Errorf("pgsql: array has more dimensions than %s", v.Type())
+			}
+			if err := assignArray(ev, elem); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := scanTextValue(ev, elem.text, elem.null); err != nil {
+			return err
+		}
+	}
+
+	v.Set(s)
+
+	return nil
+}
+
+// formatArray returns the array literal for the slice v
+func formatArray(v reflect.Value) (string, error) {
+	var b strings.Builder
+	b.WriteByte('{')
+
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		ev := v.Index(i)
+		if ev.Kind() == reflect.Slice && ev.Type() != bytesType && !ev.Type().Implements(valuerType) {
+			s, err := formatArray(ev)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(s)
+			continue
+		}
+
+		text, null, err := formatText(ev.Interface())
+		if err != nil {
+			return "", err
+		}
+
+		if null {
+			b.WriteString("NULL")
+		} else {
+			b.WriteString(quoteText(text))
+		}
+	}
+
+	b.WriteByte('}')
+
+	return b.String(), nil
+}
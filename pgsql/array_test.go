@@ -0,0 +1,131 @@
+package pgsql_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"pggen/pgsql"
+)
+
+func TestArrayScan(t *testing.T) {
+	s := func(v string) *string { return &v }
+
+	tests := []struct {
+		literal  interface{}
+		dest     interface{}
+		expected interface{}
+	}{
+		{`{1,2,3}`, new([]int), []int{1, 2, 3}},
+		{[]byte(`{-1, 2}`), new([]int64), []int64{-1, 2}},
+		{`{}`, new([]int), []int{}},
+		{`{{1,2},{3,4}}`, new([][]int), [][]int{{1, 2}, {3, 4}}},
+		{`{{{1},{2}}}`, new([][][]int), [][][]int{{{1}, {2}}}},
+		{`[0:1]={5,6}`, new([]int), []int{5, 6}},
+		{`[1:2][1:1]={{7},{8}}`, new([][]int), [][]int{{7}, {8}}},
+		{`{a,"b c","d,e","{f}"}`, new([]string), []string{"a", "b c", "d,e", "{f}"}},
+		{`{"say \"hi\"","back\\slash",un\,quoted}`, new([]string), []string{`say "hi"`, `back\slash`, "un,quoted"}},
+		{`{NULL,"NULL",null,""}`, new([]*string), []*string{nil, s("NULL"), nil, s("")}},
+		{`{NULL,x}`, new([]string), []string{"", "x"}},
+		{`{{"a",NULL},{"c",d}}`, new([][]*string), [][]*string{{s("a"), nil}, {s("c"), s("d")}}},
+		{`{t,f}`, new([]bool), []bool{true, false}},
+		{`{1.5,NaN}`, new([]pgsql.Numeric), []pgsql.Numeric{"1.5", "NaN"}},
+		{`{"2024-01-02 03:04:05+00"}`, new([]time.Time), []time.Time{time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)}},
+		{nil, &[]int{1}, []int(nil)},
+	}
+
+	for _, tt := range tests {
+		if err := pgsql.Array(tt.dest).Scan(tt.literal); err != nil {
+			t.Errorf("Array Scan(%v) returned %s", tt.literal, err)
+			continue
+		}
+
+		got := reflect.ValueOf(tt.dest).Elem().Interface()
+		if times, ok := got.([]time.Time); ok && len(times) == 1 && times[0].Equal(tt.expected.([]time.Time)[0]) {
+			continue
+		}
+
+		if !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("Array Scan(%v) = %#v, expected %#v", tt.literal, got, tt.expected)
+		}
+	}
+}
+
+func TestArrayScanInvalid(t *testing.T) {
+	tests := []struct {
+		literal string
+		dest    interface{}
+	}{
+		{`{1,2`, new([]int)},
+		{`{1,2}x`, new([]int)},
+		{`1,2`, new([]int)},
+		{`{"a}`, new([]string)},
+		{`{x}`, new([]int)},
+		{`{{1},{2}}`, new([]int)},
+		{`[0:1{1,2}`, new([]int)},
+	}
+
+	for _, tt := range tests {
+		if err := pgsql.Array(tt.dest).Scan(tt.literal); err == nil {
+			t.Errorf("Array Scan(%s) into %T accepted an invalid literal", tt.literal, tt.dest)
+		}
+	}
+
+	var n int
+	if err := pgsql.Array(&n).Scan(`{1}`); err == nil {
+		t.Errorf("Array Scan into a non slice returned no error")
+	}
+}
+
+func TestArrayValue(t *testing.T) {
+	s := func(v string) *string { return &v }
+
+	tests := []struct {
+		slice    interface{}
+		expected interface{}
+	}{
+		{[]int{1, 2, 3}, `{"1","2","3"}`},
+		{[]int{}, `{}`},
+		{[]int(nil), nil},
+		{[][]int{{1, 2}, {3, 4}}, `{{"1","2"},{"3","4"}}`},
+		{[]string{"a b", "c,d", "{e}", ""}, `{"a b","c,d","{e}",""}`},
+		{[]string{`say "hi"`, `back\slash`, "NULL"}, `{"say \"hi\"","back\\slash","NULL"}`},
+		{[]*string{s("x"), nil}, `{"x",NULL}`},
+		{&[]bool{true, false}, `{"t","f"}`},
+	}
+
+	for _, tt := range tests {
+		v, err := pgsql.Array(tt.slice).Value()
+		if err != nil || v != tt.expected {
+			t.Errorf("Array Value(%#v) = %#v, %v, expected %#v", tt.slice, v, err, tt.expected)
+			continue
+		}
+
+		if v == nil {
+			continue
+		}
+
+		dest := reflect.New(reflect.TypeOf(tt.slice))
+		if reflect.TypeOf(tt.slice).Kind() == reflect.Ptr {
+			dest = reflect.New(reflect.TypeOf(tt.slice).Elem())
+		}
+
+		if err := pgsql.Array(dest.Interface()).Scan(v); err != nil {
+			t.Errorf("Array Scan(%s) of an Array Value returned %s", v, err)
+			continue
+		}
+
+		expected := reflect.ValueOf(tt.slice)
+		if expected.Kind() == reflect.Ptr {
+			expected = expected.Elem()
+		}
+
+		if !reflect.DeepEqual(dest.Elem().Interface(), expected.Interface()) {
+			t.Errorf("Array Value(%#v) round tripped to %#v", tt.slice, dest.Elem().Interface())
+		}
+	}
+
+	if _, err := pgsql.Array(7).Value(); err == nil {
+		t.Errorf("Array Value of a non slice returned no error")
+	}
+}
@@ -0,0 +1,216 @@
+package pgsql
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrNotRun is returned by Query.Result before the query was run
+var ErrNotRun = errors.New("pgsql: query not run")
+
+// Row is a single row result, implemented by *sql.Row, *sql.Rows, pgx.Row and pgx.Rows
+type Row interface {
+	Scan(dest ...interface{}) error
+}
+
+// Rows is a result of any number of rows, implemented by *sql.Rows and pgx.Rows
+type Rows interface {
+	Next() bool
+	Scan(dest ...interface{}) error
+	Err() error
+}
+
+// Query is a statement on Table returning a T, such as the query of a generated Read. Run it on
+// its own with Run, or queue it on a Batch with other queries and read its Result after the Batch is sent
+type Query[T any] struct {
+	Table string
+	SQL   string
+	Args  []interface{}
+
+	// scanRow scans the result of a single row query, scanRows of any other
+	scanRow  func(Row) (T, error)
+	scanRows func(Rows) (T, error)
+
+	// dynamic keeps a single row query out of the statement cache of Prepared
+	dynamic bool
+
+	result T
+	err    error
+	done   bool
+}
+
+// RowQuery returns the query of a single row scanned by scan, which must call Scan on the row to
+// release its connection. The statement is one of a few, such as a constant, on lib/pq it runs
+// through the statement cache of Prepared. A statement built from its arguments is a DynamicRowQuery
+func RowQuery[T any](table string, sql string, args []interface{}, scan func(Row) (T, error)) *Query[T] {
+	return &Query[T]{Table: table, SQL: sql, Args: args, scanRow: scan}
+}
+
+// DynamicRowQuery returns the query of a single row like RowQuery, for a statement built from its
+// arguments, such as the update of a generated Patch, whose variants would fill the statement cache
+// of Prepared. On lib/pq it runs unprepared through Conn, pgx keeps it in its bounded statement cache
+func DynamicRowQuery[T any](table string, sql string, args []interface{}, scan func(Row) (T, error)) *Query[T] {
+	return &Query[T]{Table: table, SQL: sql, Args: args, scanRow: scan, dynamic: true}
+}
+
+// RowsQuery returns the query of any number of rows, each scanned by scan
+func RowsQuery[T any](table string, sql string, args []interface{}, scan func(Row) (T, error)) *Query[[]T] {
+	return &Query[[]T]{Table: table, SQL: sql, Args: args, scanRows: func(rows Rows) ([]T, error) {
+		list := []T{}
+		for rows.Next() {
+			item, err := scan(rows)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+
+		return list, rows.Err()
+	}}
+}
+
+// Run runs the query on pg, returning its result
+func (q *Query[T]) Run(ctx context.Context, pg *PgSQL) (T, error) {
+	q.result, q.err = q.run(ctx, pg)
+	q.err = Classify(q.err, q.Table)
+	q.done = true
+
+	return q.result, q.err
+}
+
+// Result returns the result of the query once run, otherwise ErrNotRun. Errors are classified by Classify
+func (q *Query[T]) Result() (T, error) {
+	if !q.done {
+		var zero T
+		return zero, ErrNotRun
+	}
+
+	return q.result, q.err
+}
+
+func (q *Query[T]) run(ctx context.Context, pg *PgSQL) (T, error) {
+	if q.scanRow != nil {
+		if conn := pg.Pgx(); conn != nil {
+			return q.scanRow(conn.QueryRow(ctx, q.SQL, q.Args...))
+		}
+
+		if q.dynamic {
+			return q.scanRow(pg.Conn().QueryRowContext(ctx, q.SQL, q.Args...))
+		}
+
+		return q.scanRow(pg.Prepared().QueryRowContext(ctx, q.SQL, q.Args...))
+	}
+
+	rows, closeRows, err := pg.query(ctx, q.SQL, q.Args)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	defer closeRows()
+
+	return q.scanRows(rows)
+}
+
+// queue adds the query to a pgx batch, setting its result when the batch response is read
+func (q *Query[T]) queue(batch *pgx.Batch) {
+	qq := batch.Queue(q.SQL, q.Args...)
+
+	if q.scanRow != nil {
+		qq.QueryRow(func(row pgx.Row) error {
+			q.result, q.err = q.scanRow(row)
+			q.err = Classify(q.err, q.Table)
+			q.done = true
+			return nil
+		})
+		return
+	}
+
+	qq.Query(func(rows pgx.Rows) error {
+		q.result, q.err = q.scanRows(rows)
+		q.err = Classify(q.err, q.Table)
+		q.done = true
+		return nil
+	})
+}
+
+// abort sets err as the result of the query when the batch failed before its response
+func (q *Query[T]) abort(err error) {
+	if !q.done {
+		q.err = Classify(err, q.Table)
+		q.done = true
+	}
+}
+
+// runBatched runs the query on its own for a Batch on lib/pq
+func (q *Query[T]) runBatched(ctx context.Context, pg *PgSQL) {
+	q.Run(ctx, pg)
+}
+
+// failed returns the error of the query once run
+func (q *Query[T]) failed() error {
+	return q.err
+}
+
+// Queued is a query that can be queued on a Batch, implemented by *Query
+type Queued interface {
+	queue(batch *pgx.Batch)
+	abort(err error)
+	runBatched(ctx context.Context, pg *PgSQL)
+	failed() error
+}
+
+// Batch sends several queries, such as the queries of generated Read and List methods, in one
+// pipelined round trip on pgx. On lib/pq the queries are run one after the other
+type Batch struct {
+	pg      *PgSQL
+	queries []Queued
+}
+
+// NewBatch returns an empty batch of queries to run on pg, or on its transaction in a RunInTx closure
+func (pg *PgSQL) NewBatch() *Batch {
+	return &Batch{pg: pg}
+}
+
+// Queue adds q to the batch, its result is read from q after Send
+func (b *Batch) Queue(q Queued) {
+	b.queries = append(b.queries, q)
+}
+
+// Len returns the number of queries queued
+func (b *Batch) Len() int {
+	return len(b.queries)
+}
+
+// Send runs the queued queries, setting their results, and returns the first error of a query.
+// On pgx a failed query aborts the queries queued after it, which return the same error
+func (b *Batch) Send(ctx context.Context) error {
+	queries := b.queries
+	b.queries = nil
+
+	if conn := b.pg.Pgx(); conn != nil {
+		batch := &pgx.Batch{}
+		for _, q := range queries {
+			q.queue(batch)
+		}
+
+		if err := conn.SendBatch(ctx, batch).Close(); err != nil {
+			for _, q := range queries {
+				q.abort(err)
+			}
+		}
+	} else {
+		for _, q := range queries {
+			q.runBatched(ctx, b.pg)
+		}
+	}
+
+	for _, q := range queries {
+		if err := q.failed(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
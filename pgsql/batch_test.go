@@ -0,0 +1,76 @@
+package pgsql_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pggen/pgsql"
+)
+
+func scanInt(row pgsql.Row) (int, error) {
+	var n int
+	err := row.Scan(&n)
+	return n, err
+}
+
+func TestBatch(t *testing.T) {
+	pg, d := newStmtPgSQL(t)
+
+	one := pgsql.RowQuery("public.member", "select 1", []interface{}{7}, scanInt)
+	list := pgsql.RowsQuery("public.member", "select n", nil, scanInt)
+
+	if _, err := one.Result(); !errors.Is(err, pgsql.ErrNotRun) {
+		t.Errorf("Result before Send = %v, expected ErrNotRun", err)
+	}
+
+	batch := pg.NewBatch()
+	batch.Queue(one)
+	batch.Queue(list)
+	if batch.Len() != 2 {
+		t.Errorf("Batch.Len() = %d, expected 2", batch.Len())
+	}
+
+	if err := batch.Send(context.Background()); err != nil {
+		t.Fatalf("Batch.Send = %v", err)
+	}
+
+	if n, err := one.Result(); n != 1 || err != nil {
+		t.Errorf("row query Result = %d, %v, expected 1", n, err)
+	}
+
+	if ns, err := list.Result(); len(ns) != 1 || ns[0] != 1 || err != nil {
+		t.Errorf("rows query Result = %v, %v, expected [1]", ns, err)
+	}
+
+	if n, err := one.Run(context.Background(), pg); n != 1 || err != nil || d.prepares != 2 {
+		t.Errorf("Run = %d, %v after %d prepares, expected the statement cached", n, err, d.prepares)
+	}
+
+	dynamic := pgsql.DynamicRowQuery("public.member", "select 1", []interface{}{7}, scanInt)
+	for i := 0; i < 2; i++ {
+		if n, err := dynamic.Run(context.Background(), pg); n != 1 || err != nil {
+			t.Errorf("dynamic Run = %d, %v, expected 1", n, err)
+		}
+	}
+
+	if d.prepares != 4 {
+		t.Errorf("2 runs of a dynamic query after %d prepares, expected 4 as it runs unprepared", d.prepares)
+	}
+
+	failing := errors.New("scan failed")
+	bad := pgsql.RowQuery("public.member", "select 1", nil, func(row pgsql.Row) (int, error) {
+		scanInt(row)
+		return 0, failing
+	})
+	batch.Queue(bad)
+	batch.Queue(one)
+
+	if err := batch.Send(context.Background()); err != failing {
+		t.Errorf("Batch.Send with a failing query = %v, expected its error", err)
+	}
+
+	if n, err := one.Result(); n != 1 || err != nil {
+		t.Errorf("Result after a failing query on lib/pq = %d, %v, expected 1", n, err)
+	}
+}
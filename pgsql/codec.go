@@ -0,0 +1,301 @@
+package pgsql
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// textLayouts are the formats postgres uses for date and time values in text output
+var textLayouts = []string{
+	"2006-01-02 15:04:05.999999999Z07:00:00",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02",
+	"15:04:05.999999999Z07:00",
+	"15:04:05.999999999Z07",
+	"15:04:05.999999999",
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+var valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+var timeType = reflect.TypeOf(time.Time{})
+var bytesType = reflect.TypeOf([]byte(nil))
+
+// srcText returns the text of a value handed to sql.Scanner.Scan by the driver
+func srcText(src interface{}) (string, error) {
+	switch v := src.(type) {
+	case []byte:
+		return string(v), nil
+	case string:
+		return v, nil
+	}
+
+	return "", fmt.Errorf("pgsql: cannot scan %T as text", src)
+}
+
+// scanText stores the postgres text representation of a value in dest, which must be a pointer.
+// A NULL sets a pointer to nil, is passed to a sql.Scanner as nil and otherwise stores the zero value
+func scanText(dest interface{}, text string, null bool) error {
+	return scanTextValue(reflect.ValueOf(dest).Elem(), text, null)
+}
+
+func scanTextValue(v reflect.Value, text string, null bool) error {
+	if v.CanAddr() && v.Addr().Type().Implements(scannerType) {
+		if null {
+			return v.Addr().Interface().(sql.Scanner).Scan(nil)
+		}
+
+		return v.Addr().Interface().(sql.Scanner).Scan([]byte(text))
+	}
+
+	if null {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		p := reflect.New(v.Type().Elem())
+		if err := scanTextValue(p.Elem(), text, false); err != nil {
+			return err
+		}
+		v.Set(p)
+		return nil
+	case reflect.String:
+		v.SetString(text)
+		return nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(text, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(text, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(text, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+		return nil
+	case reflect.Slice:
+		if v.Type() == bytesType {
+			b, err := parseBytea(text)
+			if err != nil {
+				return err
+			}
+			v.SetBytes(b)
+			return nil
+		}
+
+		node, err := parseArray(text)
+		if err != nil {
+			return err
+		}
+		return assignArray(v, node)
+	case reflect.Struct:
+		if v.Type() == timeType {
+			t, err := parseTime(text)
+			if err != nil {
+				return err
+			}
+			v.Set(reflect.ValueOf(t))
+			return nil
+		}
+	}
+
+	return fmt.Errorf("pgsql: cannot scan text into %s", v.Type())
+}
+
+// formatText returns the postgres text representation of v, null is true for nil values
+func formatText(v interface{}) (string, bool, error) {
+	if valuer, ok := v.(driver.Valuer); ok {
+		rv := reflect.ValueOf(v)
+		if rv.Kind() == reflect.Ptr && rv.IsNil() {
+			return "", true, nil
+		}
+
+		dv, err := valuer.Value()
+		if err != nil {
+			return "", false, err
+		}
+		v = dv
+	}
+
+	if v == nil {
+		return "", true, nil
+	}
+
+	switch t := v.(type) {
+	case string:
+		return t, false, nil
+	case []byte:
+		if t == nil {
+			return "", true, nil
+		}
+		return `\x` + hex.EncodeToString(t), false, nil
+	case bool:
+		if t {
+			return "t", false, nil
+		}
+		return "f", false, nil
+	case time.Time:
+		return t.Format("2006-01-02 15:04:05.999999999-07:00"), false, nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return "", true, nil
+		}
+		return formatText(rv.Elem().Interface())
+	case reflect.String:
+		return rv.String(), false, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), false, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), false, nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, rv.Type().Bits()), false, nil
+	case reflect.Slice:
+		if rv.IsNil() {
+			return "", true, nil
+		}
+		s, err := formatArray(rv)
+		return s, false, err
+	}
+
+	return "", false, fmt.Errorf("pgsql: cannot format %T as text", v)
+}
+
+// quoteText double quotes s, escaping quotes and backslashes, as accepted inside array and row literals
+func quoteText(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// parseBytea decodes the hex (\x...) or escape output formats of bytea
+func parseBytea(text string) ([]byte, error) {
+	if strings.HasPrefix(text, `\x`) {
+		return hex.DecodeString(text[2:])
+	}
+
+	b := make([]byte, 0, len(text))
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' {
+			b = append(b, text[i])
+			continue
+		}
+
+		switch {
+		case i+1 < len(text) && text[i+1] == '\\':
+			b = append(b, '\\')
+			i++
+		case i+3 < len(text):
+			n, err := strconv.ParseUint(text[i+1:i+4], 8, 8)
+			if err != nil {
+				return nil, err
+			}
+			b = append(b, byte(n))
+			i += 3
+		default:
+			return nil, errors.New("pgsql: invalid bytea escape")
+		}
+	}
+
+	return b, nil
+}
+
+// parseTime parses the text output of the date, time and timestamp types
+func parseTime(text string) (time.Time, error) {
+	bc := strings.HasSuffix(text, " BC")
+	text = strings.TrimSuffix(text, " BC")
+
+	for _, layout := range textLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			if bc {
+				t = t.AddDate(1-2*t.Year(), 0, 0)
+			}
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("pgsql: cannot parse time %q", text)
+}
+
+// literalParser walks the text of an array or row literal
+type literalParser struct {
+	text string
+	pos  int
+}
+
+func (p *literalParser) peek() byte {
+	if p.pos < len(p.text) {
+		return p.text[p.pos]
+	}
+
+	return 0
+}
+
+// value reads a possibly quoted element up to one of the terminator characters
+func (p *literalParser) value(terminators string) (string, bool, error) {
+	var b strings.Builder
+
+	if p.peek() == '"' {
+		p.pos++
+		for {
+			if p.pos >= len(p.text) {
+				return "", true, fmt.Errorf("pgsql: unterminated quoted value in %q", p.text)
+			}
+
+			c := p.text[p.pos]
+			switch {
+			case c == '\\' && p.pos+1 < len(p.text):
+				b.WriteByte(p.text[p.pos+1])
+				p.pos += 2
+			case c == '"' && p.pos+1 < len(p.text) && p.text[p.pos+1] == '"':
+				b.WriteByte('"')
+				p.pos += 2
+			case c == '"':
+				p.pos++
+				return b.String(), true, nil
+			default:
+				b.WriteByte(c)
+				p.pos++
+			}
+		}
+	}
+
+	for p.pos < len(p.text) && !strings.ContainsRune(terminators, rune(p.text[p.pos])) {
+		if p.text[p.pos] == '\\' && p.pos+1 < len(p.text) {
+			p.pos++
+		}
+		b.WriteByte(p.text[p.pos])
+		p.pos++
+	}
+
+	return strings.TrimSpace(b.String()), false, nil
+}
@@ -0,0 +1,110 @@
+package pgsql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// CompositeType models a postgres composite type created with CREATE TYPE name AS (...)
+type CompositeType struct {
+	Schema      string
+	Name        string
+	Comment     string
+	Doc         string
+	Annotations Annotations
+	Attributes  []*Column
+}
+
+// ScanComposite parses the row literal format of a composite value, e.g. ("1 Main St",Springfield,),
+// storing each attribute in the matching pointer of dest. A NULL composite stores NULL in every attribute
+func ScanComposite(src interface{}, dest ...interface{}) error {
+	if src == nil {
+		for _, d := range dest {
+			if err := scanText(d, "", true); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	text, err := srcText(src)
+	if err != nil {
+		return err
+	}
+
+	fields, nulls, err := parseComposite(text)
+	if err != nil {
+		return err
+	}
+
+	if len(fields) != len(dest) {
+		return fmt.Errorf("pgsql: composite value has %d attributes, expected %d", len(fields), len(dest))
+	}
+
+	for i, d := range dest {
+		if err := scanText(d, fields[i], nulls[i]); err != nil {
+			return fmt.Errorf("pgsql: composite attribute %d: %s", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// CompositeValue returns the row literal for the attribute values, for use in driver.Valuer implementations
+func CompositeValue(attributes ...interface{}) (driver.Value, error) {
+	var b strings.Builder
+	b.WriteByte('(')
+
+	for i, a := range attributes {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		text, null, err := formatText(a)
+		if err != nil {
+			return nil, err
+		}
+
+		if !null {
+			b.WriteString(quoteText(text))
+		}
+	}
+
+	b.WriteByte(')')
+
+	return b.String(), nil
+}
+
+// parseComposite splits a row literal into its attribute texts, an unquoted empty attribute is NULL
+func parseComposite(text string) ([]string, []bool, error) {
+	if !strings.HasPrefix(text, "(") || !strings.HasSuffix(text, ")") {
+		return nil, nil, fmt.Errorf("pgsql: invalid composite literal %q", text)
+	}
+
+	p := &literalParser{text: text, pos: 1}
+	fields := []string{}
+	nulls := []bool{}
+
+	for {
+		field, quoted, err := p.value(",)")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		fields = append(fields, field)
+		nulls = append(nulls, !quoted && len(field) == 0)
+
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ')':
+			if p.pos != len(text)-1 {
+				return nil, nil, fmt.Errorf("pgsql: unexpected %q after composite literal", text[p.pos+1:])
+			}
+			return fields, nulls, nil
+		default:
+			return nil, nil, fmt.Errorf("pgsql: unterminated composite literal %q", text)
+		}
+	}
+}
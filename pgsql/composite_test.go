@@ -0,0 +1,128 @@
+package pgsql_test
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"pggen/pgsql"
+)
+
+// point is a composite type scanned through ScanComposite, as generated code does
+type point struct {
+	X int
+	Y int
+}
+
+func (p *point) Scan(src interface{}) error {
+	return pgsql.ScanComposite(src, &p.X, &p.Y)
+}
+
+func (p point) Value() (driver.Value, error) {
+	return pgsql.CompositeValue(p.X, p.Y)
+}
+
+func TestScanComposite(t *testing.T) {
+	tests := []struct {
+		literal string
+		street  *string
+		city    *string
+	}{
+		{`(Main,Springfield)`, strPtr("Main"), strPtr("Springfield")},
+		{`("1 Main St","Spring, field")`, strPtr("1 Main St"), strPtr("Spring, field")},
+		{`("",)`, strPtr(""), nil},
+		{`(,"")`, nil, strPtr("")},
+		{`(,)`, nil, nil},
+		{`("say ""hi""","back\\slash \"q\"")`, strPtr(`say "hi"`), strPtr(`back\slash "q"`)},
+		{`("(1,2)",x)`, strPtr("(1,2)"), strPtr("x")},
+	}
+
+	for _, tt := range tests {
+		var street, city *string
+		if err := pgsql.ScanComposite(tt.literal, &street, &city); err != nil {
+			t.Errorf("ScanComposite(%s) returned %s", tt.literal, err)
+			continue
+		}
+
+		if !equalStrPtr(street, tt.street) || !equalStrPtr(city, tt.city) {
+			t.Errorf("ScanComposite(%s) = %v, %v, expected %v, %v", tt.literal, show(street), show(city), show(tt.street), show(tt.city))
+		}
+	}
+
+	var a, b *string
+	if err := pgsql.ScanComposite(nil, &a, &b); err != nil || a != nil || b != nil {
+		t.Errorf("ScanComposite of NULL = %v, %v, %v, expected NULL attributes", show(a), show(b), err)
+	}
+
+	for _, bad := range []string{`Main,Springfield`, `(Main,Springfield`, `("Main,Springfield)`, `(Main)x`, `(a,b,c)`} {
+		var street, city string
+		if err := pgsql.ScanComposite(bad, &street, &city); err == nil {
+			t.Errorf("ScanComposite(%s) accepted an invalid literal", bad)
+		}
+	}
+}
+
+func TestScanCompositeNested(t *testing.T) {
+	var label string
+	var at point
+	if err := pgsql.ScanComposite(`(origin,"(3,-4)")`, &label, &at); err != nil {
+		t.Fatalf("ScanComposite of a nested composite returned %s", err)
+	}
+
+	if label != "origin" || at != (point{3, -4}) {
+		t.Errorf("ScanComposite of a nested composite = %s, %+v", label, at)
+	}
+}
+
+func TestCompositeValue(t *testing.T) {
+	tests := []struct {
+		attributes []interface{}
+		literal    string
+	}{
+		{[]interface{}{"Main", 12}, `("Main","12")`},
+		{[]interface{}{"", nil}, `("",)`},
+		{[]interface{}{(*string)(nil), "x"}, `(,"x")`},
+		{[]interface{}{`say "hi"`, `back\slash`}, `("say \"hi\"","back\\slash")`},
+		{[]interface{}{"origin", point{3, -4}}, `("origin","(\"3\",\"-4\")")`},
+	}
+
+	for _, tt := range tests {
+		v, err := pgsql.CompositeValue(tt.attributes...)
+		if err != nil || v != tt.literal {
+			t.Errorf("CompositeValue(%v) = %v, %v, expected %s", tt.attributes, v, err, tt.literal)
+			continue
+		}
+
+		var first, second *string
+		if err := pgsql.ScanComposite(v, &first, &second); err != nil {
+			t.Errorf("ScanComposite(%s) of a CompositeValue returned %s", v, err)
+		}
+	}
+}
+
+func TestCompositeSkippedAttribute(t *testing.T) {
+	var n int
+	var skipped, null *string
+	if err := pgsql.ScanComposite(`(12,"a \"b\", c",)`, &n, &skipped, &null); err != nil {
+		t.Fatalf("ScanComposite returned %s", err)
+	}
+
+	if v, err := pgsql.CompositeValue(n, skipped, null); err != nil || v != `("12","a \"b\", c",)` {
+		t.Errorf("CompositeValue of the scanned text of skipped attributes = %v, %v, expected them unchanged", v, err)
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func equalStrPtr(a, b *string) bool {
+	return a == b || a != nil && b != nil && *a == *b
+}
+
+func show(s *string) string {
+	if s == nil {
+		return "NULL"
+	}
+
+	return `"` + *s + `"`
+}
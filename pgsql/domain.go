@@ -0,0 +1,252 @@
+package pgsql
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Domain models a postgres domain created with CREATE DOMAIN name AS base_type CHECK (...)
+type Domain struct {
+	Schema      string
+	Name        string
+	Comment     string
+	Doc         string
+	Annotations Annotations
+	NotNull     bool
+	// Base describes the underlying type of the domain
+	Base   *Column
+	Checks []*DomainCheck
+	// GoName is the name of the go type generated for the domain, empty when columns
+	// use the go type of the base type or an override instead
+	GoName string
+	// Patterns are the regular expressions of the translated checks, compiled into the
+	// package variable named by PatternsVar
+	Patterns []string
+}
+
+// PatternsVar returns the name of the generated variable holding the compiled Patterns
+func (d *Domain) PatternsVar() string {
+	return strings.ToLower(d.GoName[:1]) + d.GoName[1:] + "Patterns"
+}
+
+// DomainCheck models a check constraint of a domain. GoExpr is the go boolean expression
+// equivalent to Definition, empty when the check could not be translated
+type DomainCheck struct {
+	Name       string
+	Definition string
+	GoExpr     string
+}
+
+// namedBaseTypes are the go types that a generated domain type can be defined on
+// while still being scanned and bound by database/sql
+var namedBaseTypes = map[string]bool{
+	"string": true, "bool": true,
+	"int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"float32": true, "float64": true,
+}
+
+var castPattern = regexp.MustCompile(`\(VALUE\)::[a-z]+(?: [a-z]+)*|VALUE::[a-z]+(?: [a-z]+)*`)
+var lengthPattern = regexp.MustCompile(`^(?:char_length|character_length|length)\(\(?VALUE\)?\) (=|<>|!=|<|<=|>|>=) (.+)$`)
+var regexPattern = regexp.MustCompile(`^VALUE (~|~\*|!~|!~\*) (.+)$`)
+var comparePattern = regexp.MustCompile(`^VALUE (=|<>|!=|<|<=|>|>=) (.+)$`)
+var anyPattern = regexp.MustCompile(`^VALUE = ANY \(\(?ARRAY\[(.*?)\]\)?(?:::[a-z ]+\[\])?\)$`)
+var numberPattern = regexp.MustCompile(`^\(?(-?\d+(?:\.\d+)?)\)?(?:::[a-z ]+)?$`)
+var stringPattern = regexp.MustCompile(`^'((?:[^']|'')*)'(?:::[a-z ]+)?$`)
+
+// CheckExpr translates the definition of a domain check constraint, as returned by pg_get_constraintdef,
+// into a go boolean expression over the variable v of the generated domain type. Comparisons, length
+// limits, regular expression matches, IN lists and IS NOT NULL combined with AND and OR are supported,
+// anything else returns an error. Regular expressions are checked to compile with go regexp and
+// appended to Patterns, the expression matches through the generated PatternsVar
+func (d *Domain) CheckExpr(definition string, v string) (string, error) {
+	body := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(definition), "CHECK"))
+	body = castPattern.ReplaceAllString(body, "VALUE")
+
+	patterns := d.Patterns
+	expr, err := checkExpr(body, v, d.Base.GoType, d.PatternsVar(), &patterns)
+	if err != nil {
+		return "", err
+	}
+	d.Patterns = patterns
+
+	return expr, nil
+}
+
+func checkExpr(expr string, v string, goType string, patternsVar string, patterns *[]string) (string, error) {
+	expr = stripParens(strings.TrimSpace(expr))
+
+	for _, op := range []string{" OR ", " AND "} {
+		parts := splitTopLevel(expr, op)
+		if len(parts) < 2 {
+			continue
+		}
+
+		goOp := " || "
+		if op == " AND " {
+			goOp = " && "
+		}
+
+		exprs := make([]string, len(parts))
+		for i, part := range parts {
+			e, err := checkExpr(part, v, goType, patternsVar, patterns)
+			if err != nil {
+				return "", err
+			}
+			exprs[i] = "(" + e + ")"
+		}
+
+		return strings.Join(exprs, goOp), nil
+	}
+
+	str := fmt.Sprintf("%s(%s)", goType, v)
+
+	if expr == "VALUE IS NOT NULL" {
+		return "true", nil
+	}
+
+	if m := lengthPattern.FindStringSubmatch(expr); m != nil && goType == "string" {
+		n := numberPattern.FindStringSubmatch(m[2])
+		if n == nil {
+			return "", fmt.Errorf("unsupported length in check %s", expr)
+		}
+		return fmt.Sprintf("utf8.RuneCountInString(%s) %s %s", str, goOperator(m[1]), n[1]), nil
+	}
+
+	if m := regexPattern.FindStringSubmatch(expr); m != nil && goType == "string" {
+		s := stringPattern.FindStringSubmatch(m[2])
+		if s == nil {
+			return "", fmt.Errorf("unsupported pattern in check %s", expr)
+		}
+
+		pattern := strings.Replace(s[1], "''", "'", -1)
+		if strings.HasSuffix(m[1], "*") {
+			pattern = "(?i)" + pattern
+		}
+
+		if _, err := regexp.Compile(pattern); err != nil {
+			return "", fmt.Errorf("pattern %s is not supported by go regexp: %s", pattern, err)
+		}
+
+		*patterns = append(*patterns, pattern)
+		match := fmt.Sprintf("%s[%d].MatchString(%s)", patternsVar, len(*patterns)-1, str)
+		if strings.HasPrefix(m[1], "!") {
+			return "!" + match, nil
+		}
+		return match, nil
+	}
+
+	if m := anyPattern.FindStringSubmatch(expr); m != nil {
+		items := splitTopLevel(m[1], ", ")
+		exprs := make([]string, len(items))
+		for i, item := range items {
+			lit, err := goLiteral(item, goType)
+			if err != nil {
+				return "", err
+			}
+			exprs[i] = fmt.Sprintf("%s == %s", v, lit)
+		}
+		return strings.Join(exprs, " || "), nil
+	}
+
+	if m := comparePattern.FindStringSubmatch(expr); m != nil {
+		lit, err := goLiteral(m[2], goType)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s %s %s", v, goOperator(m[1]), lit), nil
+	}
+
+	return "", fmt.Errorf("unsupported check %s", expr)
+}
+
+// goLiteral converts a sql literal such as 'abc'::text or (0)::numeric to a go constant for goType
+func goLiteral(literal string, goType string) (string, error) {
+	literal = strings.TrimSpace(literal)
+
+	if s := stringPattern.FindStringSubmatch(literal); s != nil && goType == "string" {
+		return strconv.Quote(strings.Replace(s[1], "''", "'", -1)), nil
+	}
+
+	if n := numberPattern.FindStringSubmatch(literal); n != nil && goType != "string" && goType != "bool" {
+		return n[1], nil
+	}
+
+	if goType == "bool" && (literal == "true" || literal == "false") {
+		return literal, nil
+	}
+
+	return "", fmt.Errorf("unsupported %s literal %s", goType, literal)
+}
+
+func goOperator(op string) string {
+	switch op {
+	case "=":
+		return "=="
+	case "<>":
+		return "!="
+	}
+
+	return op
+}
+
+// stripParens removes parentheses enclosing the whole of expr
+func stripParens(expr string) string {
+	for strings.HasPrefix(expr, "(") && strings.HasSuffix(expr, ")") {
+		depth := 0
+		inQuote := false
+		closesEarly := false
+
+		for i := 0; i < len(expr)-1; i++ {
+			switch c := expr[i]; {
+			case c == '\'':
+				inQuote = !inQuote
+			case inQuote:
+			case c == '(':
+				depth++
+			case c == ')':
+				depth--
+			}
+
+			if depth == 0 && !inQuote {
+				closesEarly = true
+				break
+			}
+		}
+
+		if closesEarly {
+			return expr
+		}
+
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+
+	return expr
+}
+
+// splitTopLevel splits expr on sep where sep is outside parentheses, brackets and quotes
+func splitTopLevel(expr string, sep string) []string {
+	parts := []string{}
+	depth := 0
+	inQuote := false
+	start := 0
+
+	for i := 0; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case c == '\'':
+			inQuote = !inQuote
+		case inQuote:
+		case c == '(' || c == '[':
+			depth++
+		case c == ')' || c == ']':
+			depth--
+		case depth == 0 && strings.HasPrefix(expr[i:], sep):
+			parts = append(parts, expr[start:i])
+			start = i + len(sep)
+			i += len(sep) - 1
+		}
+	}
+
+	return append(parts, expr[start:])
+}
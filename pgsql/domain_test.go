@@ -0,0 +1,92 @@
+package pgsql_test
+
+import (
+	"testing"
+
+	"pggen/pgsql"
+)
+
+func TestCheckExpr(t *testing.T) {
+	tests := []struct {
+		goType     string
+		definition string
+		expr       string
+		patterns   []string
+	}{
+		{"string", `CHECK ((VALUE ~ '^[^@]+@[^@]+$'::text))`, `emailPatterns[0].MatchString(string(v))`, []string{"^[^@]+@[^@]+$"}},
+		{"string", `CHECK ((VALUE ~* '^[a-z]+$'::text))`, `emailPatterns[0].MatchString(string(v))`, []string{"(?i)^[a-z]+$"}},
+		{"string", `CHECK ((VALUE !~ '\s'::text))`, `!emailPatterns[0].MatchString(string(v))`, []string{`\s`}},
+		{"string", `CHECK ((VALUE ~ 'it''s'::text))`, `emailPatterns[0].MatchString(string(v))`, []string{"it's"}},
+		{"string", `CHECK ((char_length((VALUE)::text) <= 255))`, `utf8.RuneCountInString(string(v)) <= 255`, nil},
+		{"string", `CHECK ((length(VALUE) > 0))`, `utf8.RuneCountInString(string(v)) > 0`, nil},
+		{"string", `CHECK (((VALUE)::text = ANY ((ARRAY['red'::character varying, 'it''s'::character varying])::text[])))`, `v == "red" || v == "it's"`, nil},
+		{"string", `CHECK ((VALUE = ANY (ARRAY['(x)'::text, 'y, z'::text])))`, `v == "(x)" || v == "y, z"`, nil},
+		{"string", `CHECK ((VALUE <> 'a AND b'::text))`, `v != "a AND b"`, nil},
+		{"string", `CHECK ((VALUE IS NOT NULL))`, `true`, nil},
+		{"string", `CHECK (((VALUE ~ '^a'::text) AND (VALUE ~ 'z$'::text)))`, `(emailPatterns[0].MatchString(string(v))) && (emailPatterns[1].MatchString(string(v)))`, []string{"^a", "z$"}},
+		{"int", `CHECK (((VALUE >= 0) AND (VALUE <= 100)))`, `(v >= 0) && (v <= 100)`, nil},
+		{"int", `CHECK ((VALUE > 0 AND VALUE < 100))`, `(v > 0) && (v < 100)`, nil},
+		{"int", `CHECK (((VALUE < 0) OR ((VALUE > 10) AND (VALUE <> 13))))`, `(v < 0) || ((v > 10) && (v != 13))`, nil},
+		{"int", `CHECK ((VALUE = ANY (ARRAY[1, 2, 3])))`, `v == 1 || v == 2 || v == 3`, nil},
+		{"int", `CHECK ((VALUE > '-1'::integer))`, "", nil},
+		{"float64", `CHECK ((VALUE > (0)::numeric))`, `v > 0`, nil},
+		{"float64", `CHECK ((VALUE <= 99.5))`, `v <= 99.5`, nil},
+		{"bool", `CHECK ((VALUE = true))`, `v == true`, nil},
+	}
+
+	for _, tt := range tests {
+		d := &pgsql.Domain{Name: "email", GoName: "Email", Base: &pgsql.Column{GoType: tt.goType}}
+		expr, err := d.CheckExpr(tt.definition, "v")
+		if tt.expr == "" {
+			if err == nil {
+				t.Errorf("CheckExpr(%s) = %s, expected an error", tt.definition, expr)
+			}
+			continue
+		}
+
+		if err != nil || expr != tt.expr {
+			t.Errorf("CheckExpr(%s) = %s, %v, expected %s", tt.definition, expr, err, tt.expr)
+		}
+
+		if len(d.Patterns) != len(tt.patterns) {
+			t.Errorf("CheckExpr(%s) patterns = %q, expected %q", tt.definition, d.Patterns, tt.patterns)
+			continue
+		}
+
+		for i := range tt.patterns {
+			if d.Patterns[i] != tt.patterns[i] {
+				t.Errorf("CheckExpr(%s) patterns = %q, expected %q", tt.definition, d.Patterns, tt.patterns)
+			}
+		}
+	}
+}
+
+func TestCheckExprUnsupported(t *testing.T) {
+	tests := []struct {
+		goType     string
+		definition string
+	}{
+		{"string", `CHECK ((lower(VALUE) = VALUE))`},
+		{"string", `CHECK ((VALUE ~ '(?=a)b'::text))`},
+		{"string", `CHECK ((VALUE ~ '\1'::text))`},
+		{"string", `CHECK (((VALUE ~ '^a'::text) OR (lower(VALUE) = VALUE)))`},
+		{"string", `CHECK ((VALUE > 0))`},
+		{"string", `CHECK ((VALUE IS NULL))`},
+		{"string", `CHECK ((VALUE LIKE 'a%'::text))`},
+		{"int", `CHECK ((VALUE > 'x'::text))`},
+		{"int", `CHECK ((char_length((VALUE)::text) < 5))`},
+		{"int", `CHECK (((VALUE % 2) = 0))`},
+		{"int", `CHECK ((VALUE > ( SELECT 1)))`},
+	}
+
+	for _, tt := range tests {
+		d := &pgsql.Domain{Name: "email", GoName: "Email", Base: &pgsql.Column{GoType: tt.goType}}
+		if expr, err := d.CheckExpr(tt.definition, "v"); err == nil {
+			t.Errorf("CheckExpr(%s) = %s, expected an error", tt.definition, expr)
+		}
+
+		if len(d.Patterns) != 0 {
+			t.Errorf("CheckExpr(%s) kept the patterns %q of a check it refused", tt.definition, d.Patterns)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package pgsql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// Driver selects the postgres driver NewPgSQL opens its connection pool with
+type Driver string
+
+const (
+	// DriverPQ opens a database/sql pool on github.com/lib/pq, the default
+	DriverPQ Driver = "pq"
+	// DriverPgx opens a github.com/jackc/pgx/v5 pgxpool.Pool, using the binary protocol
+	// and the statement cache of pgx
+	DriverPgx Driver = "pgx"
+)
+
+// PgxDBTX is implemented by *pgxpool.Pool and pgx.Tx, stores generated for pgx run their statements through it
+type PgxDBTX interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
+
+// WithDriver selects the driver of the connection pool, DriverPQ unless given. With DriverPgx,
+// Pool is set and Db is a database/sql handle on the same pool
+func WithDriver(d Driver) Option {
+	return func(o *options) {
+		o.driver = d
+	}
+}
+
+// Pgx returns what stores generated for pgx run statements through, the transaction of a PgSQL
+// passed to a RunInTx closure, otherwise Pool. It is nil unless pg was opened with DriverPgx
+func (pg *PgSQL) Pgx() PgxDBTX {
+	if pg.tx != nil && pg.tx.pgxTx != nil {
+		return pg.tx.pgxTx
+	}
+
+	if pg.Pool == nil {
+		return nil
+	}
+
+	return pg.Pool
+}
+
+// ErrWrongDriver is returned by the statements of a store generated for pgx, with -d pgx, run on
+// a PgSQL not opened with DriverPgx
+var ErrWrongDriver = errors.New("pgsql: store generated for pgx run without DriverPgx, open the PgSQL WithDriver(DriverPgx)")
+
+// PgxStore returns what stores generated for pgx run statements through, Pgx when pg was opened
+// with DriverPgx. Otherwise every statement run through it fails with ErrWrongDriver
+func (pg *PgSQL) PgxStore() PgxDBTX {
+	if conn := pg.Pgx(); conn != nil {
+		return conn
+	}
+
+	return wrongDriver{}
+}
+
+// wrongDriver is the PgxDBTX of PgxStore without DriverPgx, failing with ErrWrongDriver
+type wrongDriver struct{}
+
+// Exec implements PgxDBTX
+func (wrongDriver) Exec(context.Context, string, ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, ErrWrongDriver
+}
+
+// Query implements PgxDBTX
+func (wrongDriver) Query(context.Context, string, ...any) (pgx.Rows, error) {
+	return nil, ErrWrongDriver
+}
+
+// QueryRow implements PgxDBTX
+func (wrongDriver) QueryRow(context.Context, string, ...any) pgx.Row {
+	return wrongDriver{}
+}
+
+// SendBatch implements PgxDBTX
+func (wrongDriver) SendBatch(context.Context, *pgx.Batch) pgx.BatchResults {
+	return wrongDriverBatch{}
+}
+
+// Scan implements pgx.Row
+func (wrongDriver) Scan(...any) error {
+	return ErrWrongDriver
+}
+
+// wrongDriverBatch is the pgx.BatchResults of wrongDriver
+type wrongDriverBatch struct{}
+
+func (wrongDriverBatch) Exec() (pgconn.CommandTag, error) { return pgconn.CommandTag{}, ErrWrongDriver }
+func (wrongDriverBatch) Query() (pgx.Rows, error)         { return nil, ErrWrongDriver }
+func (wrongDriverBatch) QueryRow() pgx.Row                { return wrongDriver{} }
+func (wrongDriverBatch) Close() error                     { return ErrWrongDriver }
+
+// PgxTx returns the pgx transaction of a PgSQL passed to a RunInTx closure, nil otherwise
+func (pg *PgSQL) PgxTx() pgx.Tx {
+	if pg.tx == nil {
+		return nil
+	}
+
+	return pg.tx.pgxTx
+}
+
+// Close closes the connection pool
+func (pg *PgSQL) Close() error {
+	pg.ClearStatementCache()
+	err := pg.Db.Close()
+	if pg.Pool != nil {
+		pg.Pool.Close()
+	}
+
+	return err
+}
+
+// newPgxPgSQL opens a pgxpool.Pool configured by o
+func newPgxPgSQL(connectionString string, o *options) (*PgSQL, error) {
+	config, err := pgxpool.ParseConfig(connectionString)
+	if err != nil {
+		return nil, fmt.Errorf("pgsql: %w", err)
+	}
+	o.configurePgx(config)
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), config)
+	if err != nil {
+		return nil, fmt.Errorf("pgsql: %w", err)
+	}
+
+	pg := &PgSQL{Db: stdlib.OpenDBFromPool(pool), Pool: pool}
+	if err := o.check(pg); err != nil {
+		pg.Close()
+		return nil, err
+	}
+
+	return pg, nil
+}
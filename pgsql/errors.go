@@ -0,0 +1,275 @@
+package pgsql
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+// Sentinel errors matched by errors.Is for the typed errors returned by Classify
+var (
+	ErrNotFound             = errors.New("pgsql: no row matched")
+	ErrUniqueViolation      = errors.New("pgsql: unique violation")
+	ErrForeignKeyViolation  = errors.New("pgsql: foreign key violation")
+	ErrCheckViolation       = errors.New("pgsql: check violation")
+	ErrSerializationFailure = errors.New("pgsql: serialization failure")
+	ErrDeadlock             = errors.New("pgsql: deadlock detected")
+	ErrStaleObject          = errors.New("pgsql: stale object")
+)
+
+// SQLSTATE codes classified by Classify
+const (
+	uniqueViolation      = "23505"
+	foreignKeyViolation  = "23503"
+	checkViolation       = "23514"
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// NotFoundError reports that a generated Read, Update or Delete matched no row of Table. It matches
+// ErrNotFound and, when caused by a query returning no row, sql.ErrNoRows
+type NotFoundError struct {
+	// Table is the schema qualified table name, e.g. public.member
+	Table string
+	// Err is the underlying error, sql.ErrNoRows or nil
+	Err error
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("pgsql: no row of %s matched", e.Table)
+}
+
+// Is implements errors.Is, matching ErrNotFound
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// Unwrap returns the underlying error
+func (e *NotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// NotFound returns a *NotFoundError for table when err is sql.ErrNoRows, otherwise err
+func NotFound(err error, table string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return &NotFoundError{Table: table, Err: err}
+	}
+
+	return err
+}
+
+// StaleObjectError reports that a generated Update, Patch or Delete of a versioned Table matched
+// no row with the version given, the row was changed or deleted since it was read. It matches
+// ErrStaleObject and, when caused by a query returning no row, sql.ErrNoRows
+type StaleObjectError struct {
+	// Table is the schema qualified table name, e.g. public.site
+	Table string
+	// Err is the underlying error, sql.ErrNoRows or nil
+	Err error
+}
+
+func (e *StaleObjectError) Error() string {
+	return fmt.Sprintf("pgsql: %s row changed or deleted since it was read", e.Table)
+}
+
+// Is implements errors.Is, matching ErrStaleObject
+func (e *StaleObjectError) Is(target error) bool {
+	return target == ErrStaleObject
+}
+
+// Unwrap returns the underlying error
+func (e *StaleObjectError) Unwrap() error {
+	return e.Err
+}
+
+// Stale returns a *StaleObjectError for table when err is sql.ErrNoRows or a *NotFoundError, as
+// returned by Run, otherwise err classified by Classify
+func Stale(err error, table string) error {
+	var notFound *NotFoundError
+	if errors.As(err, &notFound) {
+		return &StaleObjectError{Table: table, Err: notFound.Err}
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &StaleObjectError{Table: table, Err: err}
+	}
+
+	return Classify(err, table)
+}
+
+// UniqueViolation reports that a write to Table duplicated the key of the unique index or
+// constraint Constraint on Columns
+type UniqueViolation struct {
+	Table      string
+	Constraint string
+	Columns    []string
+	Err        error
+}
+
+func (e *UniqueViolation) Error() string {
+	return fmt.Sprintf("pgsql: %s: duplicate (%s) violates unique constraint %s", e.Table, strings.Join(e.Columns, ", "), e.Constraint)
+}
+
+// Is implements errors.Is, matching ErrUniqueViolation
+func (e *UniqueViolation) Is(target error) bool {
+	return target == ErrUniqueViolation
+}
+
+// Unwrap returns the driver error
+func (e *UniqueViolation) Unwrap() error {
+	return e.Err
+}
+
+// ForeignKeyViolation reports that a write to Table referenced a missing row, or that a delete
+// left rows referencing it, through the foreign key Constraint
+type ForeignKeyViolation struct {
+	Table      string
+	Constraint string
+	Err        error
+}
+
+func (e *ForeignKeyViolation) Error() string {
+	return fmt.Sprintf("pgsql: %s: violates foreign key constraint %s", e.Table, e.Constraint)
+}
+
+// Is implements errors.Is, matching ErrForeignKeyViolation
+func (e *ForeignKeyViolation) Is(target error) bool {
+	return target == ErrForeignKeyViolation
+}
+
+// Unwrap returns the driver error
+func (e *ForeignKeyViolation) Unwrap() error {
+	return e.Err
+}
+
+// CheckViolation reports that a write to Table failed the check constraint Constraint
+type CheckViolation struct {
+	Table      string
+	Constraint string
+	Err        error
+}
+
+func (e *CheckViolation) Error() string {
+	return fmt.Sprintf("pgsql: %s: violates check constraint %s", e.Table, e.Constraint)
+}
+
+// Is implements errors.Is, matching ErrCheckViolation
+func (e *CheckViolation) Is(target error) bool {
+	return target == ErrCheckViolation
+}
+
+// Unwrap returns the driver error
+func (e *CheckViolation) Unwrap() error {
+	return e.Err
+}
+
+// SerializationFailure reports that a statement on Table could not be serialized with concurrent
+// transactions, the transaction may be retried
+type SerializationFailure struct {
+	Table string
+	Err   error
+}
+
+func (e *SerializationFailure) Error() string {
+	return fmt.Sprintf("pgsql: %s: could not serialize access due to concurrent update", e.Table)
+}
+
+// Is implements errors.Is, matching ErrSerializationFailure
+func (e *SerializationFailure) Is(target error) bool {
+	return target == ErrSerializationFailure
+}
+
+// Unwrap returns the driver error
+func (e *SerializationFailure) Unwrap() error {
+	return e.Err
+}
+
+// Deadlock reports that a statement on Table was chosen as the victim of a deadlock, the
+// transaction may be retried
+type Deadlock struct {
+	Table string
+	Err   error
+}
+
+func (e *Deadlock) Error() string {
+	return fmt.Sprintf("pgsql: %s: deadlock detected", e.Table)
+}
+
+// Is implements errors.Is, matching ErrDeadlock
+func (e *Deadlock) Is(target error) bool {
+	return target == ErrDeadlock
+}
+
+// Unwrap returns the driver error
+func (e *Deadlock) Unwrap() error {
+	return e.Err
+}
+
+// Classify wraps err, returned by a statement on table, into the typed error for its cause:
+// *NotFoundError for sql.ErrNoRows, and *UniqueViolation, *ForeignKeyViolation, *CheckViolation,
+// *SerializationFailure or *Deadlock for the matching postgres errors of either driver. Other
+// errors, including nil, are returned unchanged
+func Classify(err error, table string) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &NotFoundError{Table: table, Err: err}
+	}
+
+	code, constraint, detail, _, ok := serverError(err)
+	if !ok {
+		return err
+	}
+
+	switch code {
+	case uniqueViolation:
+		return &UniqueViolation{Table: table, Constraint: constraint, Columns: keyColumns(detail), Err: err}
+	case foreignKeyViolation:
+		return &ForeignKeyViolation{Table: table, Constraint: constraint, Err: err}
+	case checkViolation:
+		return &CheckViolation{Table: table, Constraint: constraint, Err: err}
+	case serializationFailure:
+		return &SerializationFailure{Table: table, Err: err}
+	case deadlockDetected:
+		return &Deadlock{Table: table, Err: err}
+	}
+
+	return err
+}
+
+// serverError returns the SQLSTATE code, constraint, detail and message of an error reported by
+// the server through either driver, a *pq.Error or a *pgconn.PgError
+func serverError(err error) (code, constraint, detail, message string, ok bool) {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return string(pqErr.Code), pqErr.Constraint, pqErr.Detail, pqErr.Message, true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code, pgErr.ConstraintName, pgErr.Detail, pgErr.Message, true
+	}
+
+	return "", "", "", "", false
+}
+
+// keyColumns returns the columns named by the detail of a unique violation,
+// e.g. email and site from: Key (email, site)=(a@example.com, 1) already exists.
+func keyColumns(detail string) []string {
+	if !strings.HasPrefix(detail, "Key (") {
+		return nil
+	}
+
+	end := strings.Index(detail, ")=(")
+	if end < 0 {
+		return nil
+	}
+
+	return strings.Split(detail[len("Key ("):end], ", ")
+}
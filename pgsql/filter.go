@@ -0,0 +1,118 @@
+package pgsql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Filter restricts the rows returned by generated List methods. Expr is an sql boolean expression
+// with a ? placeholder for each of Args, e.g. Filter{Expr: "during && ?::tstzrange", Args: []interface{}{r}}.
+// A ? inside a quoted string literal or identifier is kept, and ?? stands for a literal ?, such as the
+// jsonb operators ?, ?| and ?&, e.g. Filter{Expr: "tags ?? ?", Args: []interface{}{"x"}}. The zero
+// Filter matches every row
+type Filter struct {
+	Expr string
+	Args []interface{}
+}
+
+// And returns the filter matching the rows matched by every one of filters
+func And(filters ...Filter) Filter {
+	return join(" and ", filters)
+}
+
+// Or returns the filter matching the rows matched by any of filters
+func Or(filters ...Filter) Filter {
+	return join(" or ", filters)
+}
+
+func join(op string, filters []Filter) Filter {
+	exprs := []string{}
+	var args []interface{}
+
+	for _, f := range filters {
+		if len(f.Expr) == 0 {
+			continue
+		}
+		exprs = append(exprs, "("+f.Expr+")")
+		args = append(args, f.Args...)
+	}
+
+	return Filter{Expr: strings.Join(exprs, op), Args: args}
+}
+
+// Where returns the filter as a where clause, " where expr", with its placeholders numbered
+// from $start. The zero Filter returns an empty string
+func (f Filter) Where(start int) string {
+	if len(f.Expr) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(" where ")
+
+	n := start
+	var quote byte
+	for i := 0; i < len(f.Expr); i++ {
+		c := f.Expr[i]
+
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '?' && i+1 < len(f.Expr) && f.Expr[i+1] == '?':
+			i++
+		case c == '?':
+			fmt.Fprintf(&b, "$%d", n)
+			n++
+			continue
+		}
+
+		b.WriteByte(c)
+	}
+
+	return b.String()
+}
+
+// ListOptions orders and pages the rows returned by generated List methods
+type ListOptions struct {
+	// OrderBy is an sql order by list such as "created desc, id", it must not hold user input
+	OrderBy string
+	// Limit is the maximum number of rows returned, 0 for all rows
+	Limit  int
+	Offset int
+}
+
+// Clause returns the order by, limit and offset clauses for the options, with the limit and offset
+// bound as the placeholders $start and $start+1 so that the statement does not change with them
+func (o ListOptions) Clause(start int) string {
+	var b strings.Builder
+
+	if len(o.OrderBy) > 0 {
+		b.WriteString(" order by " + o.OrderBy)
+	}
+
+	if o.Limit > 0 || o.Offset > 0 {
+		fmt.Fprintf(&b, " limit $%d offset $%d", start, start+1)
+	}
+
+	return b.String()
+}
+
+// Args returns the arguments before the clause followed by those of Clause, in a new slice.
+// A zero Limit is bound as NULL, which postgres reads as no limit
+func (o ListOptions) Args(before []interface{}) []interface{} {
+	args := append([]interface{}{}, before...)
+
+	if o.Limit > 0 || o.Offset > 0 {
+		var limit interface{}
+		if o.Limit > 0 {
+			limit = int64(o.Limit)
+		}
+		args = append(args, limit, int64(o.Offset))
+	}
+
+	return args
+}
@@ -0,0 +1,254 @@
+package pgsql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Point models a postgres point (x,y)
+type Point struct {
+	X float64
+	Y float64
+}
+
+// LSeg models a postgres line segment [(x1,y1),(x2,y2)]
+type LSeg [2]Point
+
+// Box models a postgres box (x1,y1),(x2,y2). Postgres stores the upper right corner first
+type Box [2]Point
+
+// Path models a postgres path, open [(x1,y1),...] or closed ((x1,y1),...). A nil Points is NULL
+type Path struct {
+	Points []Point
+	Closed bool
+}
+
+// Polygon models a postgres polygon ((x1,y1),...), a nil Polygon is NULL
+type Polygon []Point
+
+// Circle models a postgres circle <(x,y),r>
+type Circle struct {
+	Center Point
+	Radius float64
+}
+
+var geometricNumber = regexp.MustCompile(`[-+]?(?:\d+\.?\d*|\.\d+)(?:[eE][-+]?\d+)?|[-+]?Infinity|NaN`)
+
+// geometricNumbers returns the numbers in the text output of a geometric type, in order
+func geometricNumbers(src interface{}, kind string) ([]float64, string, error) {
+	text, err := srcText(src)
+	if err != nil {
+		return nil, "", err
+	}
+
+	matches := geometricNumber.FindAllString(text, -1)
+	numbers := make([]float64, len(matches))
+	for i, m := range matches {
+		if numbers[i], err = strconv.ParseFloat(m, 64); err != nil {
+			return nil, "", fmt.Errorf("pgsql: invalid %s %q", kind, text)
+		}
+	}
+
+	return numbers, strings.TrimSpace(text), nil
+}
+
+// geometricPoints returns the points in the text output of a geometric type
+func geometricPoints(src interface{}, kind string) ([]Point, string, error) {
+	numbers, text, err := geometricNumbers(src, kind)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if len(numbers)%2 != 0 {
+		return nil, "", fmt.Errorf("pgsql: invalid %s %q", kind, text)
+	}
+
+	points := make([]Point, len(numbers)/2)
+	for i := range points {
+		points[i] = Point{X: numbers[2*i], Y: numbers[2*i+1]}
+	}
+
+	return points, text, nil
+}
+
+// formatFloat formats a coordinate as postgres accepts it
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func (p Point) String() string {
+	return "(" + formatFloat(p.X) + "," + formatFloat(p.Y) + ")"
+}
+
+// formatPoints joins points as (x1,y1),(x2,y2)
+func formatPoints(points []Point) string {
+	s := make([]string, len(points))
+	for i, p := range points {
+		s[i] = p.String()
+	}
+
+	return strings.Join(s, ",")
+}
+
+// Scan implements sql.Scanner
+func (p *Point) Scan(src interface{}) error {
+	if src == nil {
+		*p = Point{}
+		return nil
+	}
+
+	points, text, err := geometricPoints(src, "point")
+	if err != nil {
+		return err
+	}
+
+	if len(points) != 1 {
+		return fmt.Errorf("pgsql: invalid point %q", text)
+	}
+
+	*p = points[0]
+
+	return nil
+}
+
+// Value implements driver.Valuer
+func (p Point) Value() (driver.Value, error) {
+	return p.String(), nil
+}
+
+// Scan implements sql.Scanner
+func (l *LSeg) Scan(src interface{}) error {
+	if src == nil {
+		*l = LSeg{}
+		return nil
+	}
+
+	points, text, err := geometricPoints(src, "lseg")
+	if err != nil {
+		return err
+	}
+
+	if len(points) != 2 {
+		return fmt.Errorf("pgsql: invalid lseg %q", text)
+	}
+
+	*l = LSeg{points[0], points[1]}
+
+	return nil
+}
+
+// Value implements driver.Valuer
+func (l LSeg) Value() (driver.Value, error) {
+	return "[" + formatPoints(l[:]) + "]", nil
+}
+
+// Scan implements sql.Scanner
+func (b *Box) Scan(src interface{}) error {
+	if src == nil {
+		*b = Box{}
+		return nil
+	}
+
+	points, text, err := geometricPoints(src, "box")
+	if err != nil {
+		return err
+	}
+
+	if len(points) != 2 {
+		return fmt.Errorf("pgsql: invalid box %q", text)
+	}
+
+	*b = Box{points[0], points[1]}
+
+	return nil
+}
+
+// Value implements driver.Valuer
+func (b Box) Value() (driver.Value, error) {
+	return formatPoints(b[:]), nil
+}
+
+// Scan implements sql.Scanner
+func (p *Path) Scan(src interface{}) error {
+	if src == nil {
+		*p = Path{}
+		return nil
+	}
+
+	points, text, err := geometricPoints(src, "path")
+	if err != nil {
+		return err
+	}
+
+	*p = Path{Points: points, Closed: !strings.HasPrefix(text, "[")}
+
+	return nil
+}
+
+// Value implements driver.Valuer
+func (p Path) Value() (driver.Value, error) {
+	if p.Points == nil {
+		return nil, nil
+	}
+
+	if p.Closed {
+		return "(" + formatPoints(p.Points) + ")", nil
+	}
+
+	return "[" + formatPoints(p.Points) + "]", nil
+}
+
+// Scan implements sql.Scanner
+func (p *Polygon) Scan(src interface{}) error {
+	if src == nil {
+		*p = nil
+		return nil
+	}
+
+	points, _, err := geometricPoints(src, "polygon")
+	if err != nil {
+		return err
+	}
+
+	*p = points
+
+	return nil
+}
+
+// Value implements driver.Valuer
+func (p Polygon) Value() (driver.Value, error) {
+	if p == nil {
+		return nil, nil
+	}
+
+	return "(" + formatPoints(p) + ")", nil
+}
+
+// Scan implements sql.Scanner
+func (c *Circle) Scan(src interface{}) error {
+	if src == nil {
+		*c = Circle{}
+		return nil
+	}
+
+	numbers, text, err := geometricNumbers(src, "circle")
+	if err != nil {
+		return err
+	}
+
+	if len(numbers) != 3 {
+		return fmt.Errorf("pgsql: invalid circle %q", text)
+	}
+
+	*c = Circle{Center: Point{X: numbers[0], Y: numbers[1]}, Radius: numbers[2]}
+
+	return nil
+}
+
+// Value implements driver.Valuer
+func (c Circle) Value() (driver.Value, error) {
+	return "<" + c.Center.String() + "," + formatFloat(c.Radius) + ">", nil
+}
@@ -0,0 +1,354 @@
+package pgsql
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Geometry models a PostGIS geometry or geography value, decoded from the extended well known
+// binary (EWKB) format PostGIS outputs. SRID is 0 when the value has no spatial reference system.
+// Only two dimensional values are supported, scanning a value with Z or M coordinates fails
+type Geometry struct {
+	SRID  int
+	Shape Shape
+}
+
+// Shape is the shape of a Geometry: a Point, LineString, GeoPolygon, MultiPoint, MultiLineString,
+// MultiPolygon or GeometryCollection
+type Shape interface {
+	wkbType() uint32
+}
+
+// LineString is a PostGIS linestring
+type LineString []Point
+
+// GeoPolygon is a PostGIS polygon, its outer ring followed by any holes. Rings are closed, their
+// last point equals the first
+type GeoPolygon [][]Point
+
+// MultiPoint is a PostGIS multipoint
+type MultiPoint []Point
+
+// MultiLineString is a PostGIS multilinestring
+type MultiLineString []LineString
+
+// MultiPolygon is a PostGIS multipolygon
+type MultiPolygon []GeoPolygon
+
+// GeometryCollection is a PostGIS geometrycollection
+type GeometryCollection []Shape
+
+func (Point) wkbType() uint32              { return 1 }
+func (LineString) wkbType() uint32         { return 2 }
+func (GeoPolygon) wkbType() uint32         { return 3 }
+func (MultiPoint) wkbType() uint32         { return 4 }
+func (MultiLineString) wkbType() uint32    { return 5 }
+func (MultiPolygon) wkbType() uint32       { return 6 }
+func (GeometryCollection) wkbType() uint32 { return 7 }
+
+// EWKB type flags
+const (
+	ewkbZ    = 0x80000000
+	ewkbM    = 0x40000000
+	ewkbSRID = 0x20000000
+)
+
+// Scan implements sql.Scanner, accepting hex encoded EWKB as returned in text format and raw EWKB.
+// NULL scans as the zero Geometry
+func (g *Geometry) Scan(src interface{}) error {
+	if src == nil {
+		*g = Geometry{}
+		return nil
+	}
+
+	var b []byte
+	switch v := src.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("pgsql: cannot scan %T as geometry", src)
+	}
+
+	// raw EWKB starts with its byte order, 0 or 1, hex EWKB with the digit 0
+	if len(b) > 0 && b[0] > 1 {
+		decoded, err := hex.DecodeString(string(b))
+		if err != nil {
+			return fmt.Errorf("pgsql: invalid geometry: %s", err)
+		}
+		b = decoded
+	}
+
+	r := &wkbReader{b: b}
+	shape, srid, err := r.shape()
+	if err != nil {
+		return err
+	}
+
+	if r.pos != len(b) {
+		return errors.New("pgsql: unexpected bytes after geometry")
+	}
+
+	*g = Geometry{SRID: int(srid), Shape: shape}
+
+	return nil
+}
+
+// Value implements driver.Valuer, returning hex encoded EWKB. A Geometry without a Shape is NULL
+func (g Geometry) Value() (driver.Value, error) {
+	if g.Shape == nil {
+		return nil, nil
+	}
+
+	w := &wkbWriter{}
+	if err := w.shape(g.Shape, uint32(g.SRID)); err != nil {
+		return nil, err
+	}
+
+	return hex.EncodeToString(w.b), nil
+}
+
+// wkbReader decodes EWKB
+type wkbReader struct {
+	b     []byte
+	pos   int
+	order binary.ByteOrder
+}
+
+func (r *wkbReader) uint32() (uint32, error) {
+	if r.pos+4 > len(r.b) {
+		return 0, errors.New("pgsql: truncated geometry")
+	}
+	v := r.order.Uint32(r.b[r.pos:])
+	r.pos += 4
+
+	return v, nil
+}
+
+func (r *wkbReader) point() (Point, error) {
+	if r.pos+16 > len(r.b) {
+		return Point{}, errors.New("pgsql: truncated geometry")
+	}
+	p := Point{
+		X: math.Float64frombits(r.order.Uint64(r.b[r.pos:])),
+		Y: math.Float64frombits(r.order.Uint64(r.b[r.pos+8:])),
+	}
+	r.pos += 16
+
+	return p, nil
+}
+
+func (r *wkbReader) points() ([]Point, error) {
+	n, err := r.uint32()
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]Point, 0, n)
+	for i := uint32(0); i < n; i++ {
+		p, err := r.point()
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, p)
+	}
+
+	return points, nil
+}
+
+func (r *wkbReader) count() (int, error) {
+	n, err := r.uint32()
+	if int(n) > len(r.b) {
+		return 0, errors.New("pgsql: truncated geometry")
+	}
+
+	return int(n), err
+}
+
+// shape reads a geometry and its SRID, 0 when absent
+func (r *wkbReader) shape() (Shape, uint32, error) {
+	if r.pos >= len(r.b) {
+		return nil, 0, errors.New("pgsql: truncated geometry")
+	}
+
+	switch r.b[r.pos] {
+	case 0:
+		r.order = binary.BigEndian
+	case 1:
+		r.order = binary.LittleEndian
+	default:
+		return nil, 0, fmt.Errorf("pgsql: invalid geometry byte order %d", r.b[r.pos])
+	}
+	r.pos++
+
+	t, err := r.uint32()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if t&(ewkbZ|ewkbM) != 0 || t&0xffff > 7 {
+		return nil, 0, errors.New("pgsql: only two dimensional geometries are supported")
+	}
+
+	var srid uint32
+	if t&ewkbSRID != 0 {
+		if srid, err = r.uint32(); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	switch t & 0xff {
+	case 1:
+		p, err := r.point()
+		return p, srid, err
+	case 2:
+		points, err := r.points()
+		return LineString(points), srid, err
+	case 3:
+		n, err := r.count()
+		if err != nil {
+			return nil, 0, err
+		}
+		polygon := make(GeoPolygon, 0, n)
+		for i := 0; i < n; i++ {
+			ring, err := r.points()
+			if err != nil {
+				return nil, 0, err
+			}
+			polygon = append(polygon, ring)
+		}
+		return polygon, srid, nil
+	}
+
+	n, err := r.count()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	shapes := make([]Shape, 0, n)
+	for i := 0; i < n; i++ {
+		s, _, err := r.shape()
+		if err != nil {
+			return nil, 0, err
+		}
+		shapes = append(shapes, s)
+	}
+
+	switch t & 0xff {
+	case 4:
+		multi := make(MultiPoint, len(shapes))
+		for i, s := range shapes {
+			p, ok := s.(Point)
+			if !ok {
+				return nil, 0, errors.New("pgsql: multipoint holds a non point")
+			}
+			multi[i] = p
+		}
+		return multi, srid, nil
+	case 5:
+		multi := make(MultiLineString, len(shapes))
+		for i, s := range shapes {
+			l, ok := s.(LineString)
+			if !ok {
+				return nil, 0, errors.New("pgsql: multilinestring holds a non linestring")
+			}
+			multi[i] = l
+		}
+		return multi, srid, nil
+	case 6:
+		multi := make(MultiPolygon, len(shapes))
+		for i, s := range shapes {
+			p, ok := s.(GeoPolygon)
+			if !ok {
+				return nil, 0, errors.New("pgsql: multipolygon holds a non polygon")
+			}
+			multi[i] = p
+		}
+		return multi, srid, nil
+	}
+
+	return GeometryCollection(shapes), srid, nil
+}
+
+// wkbWriter encodes little endian EWKB
+type wkbWriter struct {
+	b []byte
+}
+
+func (w *wkbWriter) uint32(v uint32) {
+	w.b = binary.LittleEndian.AppendUint32(w.b, v)
+}
+
+func (w *wkbWriter) points(points []Point) {
+	w.uint32(uint32(len(points)))
+	for _, p := range points {
+		w.point(p)
+	}
+}
+
+func (w *wkbWriter) point(p Point) {
+	w.b = binary.LittleEndian.AppendUint64(w.b, math.Float64bits(p.X))
+	w.b = binary.LittleEndian.AppendUint64(w.b, math.Float64bits(p.Y))
+}
+
+// shape writes s, with srid when it is not 0
+func (w *wkbWriter) shape(s Shape, srid uint32) error {
+	w.b = append(w.b, 1)
+
+	t := s.wkbType()
+	if srid != 0 {
+		w.uint32(t | ewkbSRID)
+		w.uint32(srid)
+	} else {
+		w.uint32(t)
+	}
+
+	var parts []Shape
+	switch v := s.(type) {
+	case Point:
+		w.point(v)
+		return nil
+	case LineString:
+		w.points(v)
+		return nil
+	case GeoPolygon:
+		w.uint32(uint32(len(v)))
+		for _, ring := range v {
+			w.points(ring)
+		}
+		return nil
+	case MultiPoint:
+		for _, p := range v {
+			parts = append(parts, p)
+		}
+	case MultiLineString:
+		for _, l := range v {
+			parts = append(parts, l)
+		}
+	case MultiPolygon:
+		for _, p := range v {
+			parts = append(parts, p)
+		}
+	case GeometryCollection:
+		parts = v
+	default:
+		return fmt.Errorf("pgsql: cannot encode geometry %T", s)
+	}
+
+	w.uint32(uint32(len(parts)))
+	for _, p := range parts {
+		if p == nil {
+			return errors.New("pgsql: geometry collection holds a nil shape")
+		}
+		if err := w.shape(p, 0); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package pgsql_test
+
+import (
+	"testing"
+
+	"pggen/pgsql"
+)
+
+func TestGeometric(t *testing.T) {
+	var p pgsql.Point
+	if err := p.Scan([]byte("(1.5,-2)")); err != nil || p != (pgsql.Point{X: 1.5, Y: -2}) {
+		t.Errorf("Point Scan = %v, %v", p, err)
+	}
+
+	if v, err := p.Value(); err != nil || v != "(1.5,-2)" {
+		t.Errorf("Point Value = %v", v)
+	}
+
+	var l pgsql.LSeg
+	if err := l.Scan("[(0,0),(1e+20,2)]"); err != nil || l != (pgsql.LSeg{{X: 0, Y: 0}, {X: 1e20, Y: 2}}) {
+		t.Errorf("LSeg Scan = %v, %v", l, err)
+	}
+
+	var b pgsql.Box
+	if err := b.Scan("(2,2),(0,0)"); err != nil || b != (pgsql.Box{{X: 2, Y: 2}, {X: 0, Y: 0}}) {
+		t.Errorf("Box Scan = %v, %v", b, err)
+	}
+
+	if v, err := b.Value(); err != nil || v != "(2,2),(0,0)" {
+		t.Errorf("Box Value = %v", v)
+	}
+
+	paths := map[string]bool{"[(0,0),(1,1)]": false, "((0,0),(1,1))": true}
+	for text, closed := range paths {
+		var path pgsql.Path
+		if err := path.Scan(text); err != nil || path.Closed != closed || len(path.Points) != 2 {
+			t.Errorf("Path Scan(%q) = %v, %v", text, path, err)
+		}
+
+		if v, err := path.Value(); err != nil || v != text {
+			t.Errorf("Path Value after Scan(%q) = %v", text, v)
+		}
+	}
+
+	var polygon pgsql.Polygon
+	if err := polygon.Scan("((0,0),(4,0),(4,3))"); err != nil || len(polygon) != 3 || polygon[2] != (pgsql.Point{X: 4, Y: 3}) {
+		t.Errorf("Polygon Scan = %v, %v", polygon, err)
+	}
+
+	if v, err := polygon.Value(); err != nil || v != "((0,0),(4,0),(4,3))" {
+		t.Errorf("Polygon Value = %v", v)
+	}
+
+	if v, err := pgsql.Polygon(nil).Value(); err != nil || v != nil {
+		t.Errorf("Value of a nil Polygon = %v, expected NULL", v)
+	}
+
+	var c pgsql.Circle
+	if err := c.Scan("<(1,2),3.5>"); err != nil || c != (pgsql.Circle{Center: pgsql.Point{X: 1, Y: 2}, Radius: 3.5}) {
+		t.Errorf("Circle Scan = %v, %v", c, err)
+	}
+
+	if v, err := c.Value(); err != nil || v != "<(1,2),3.5>" {
+		t.Errorf("Circle Value = %v", v)
+	}
+
+	if err := p.Scan("(1,2),(3,4)"); err == nil {
+		t.Errorf("Point Scan of a box did not return an error")
+	}
+}
+
+func TestGeometry(t *testing.T) {
+	// SELECT 'SRID=4326;POINT(1 2)'::geometry
+	var g pgsql.Geometry
+	if err := g.Scan([]byte("0101000020E6100000000000000000F03F0000000000000040")); err != nil {
+		t.Fatalf("Scan returned error %s", err)
+	}
+
+	if g.SRID != 4326 || g.Shape != (pgsql.Point{X: 1, Y: 2}) {
+		t.Errorf("Scan = %v", g)
+	}
+
+	if v, err := g.Value(); err != nil || v != "0101000020e6100000000000000000f03f0000000000000040" {
+		t.Errorf("Value = %v, %v", v, err)
+	}
+
+	shapes := []pgsql.Geometry{
+		{Shape: pgsql.LineString{{X: 0, Y: 0}, {X: 1, Y: 1}}},
+		{SRID: 3857, Shape: pgsql.GeoPolygon{{{X: 0, Y: 0}, {X: 4, Y: 0}, {X: 4, Y: 4}, {X: 0, Y: 0}}, {{X: 1, Y: 1}, {X: 2, Y: 1}, {X: 2, Y: 2}, {X: 1, Y: 1}}}},
+		{SRID: 4326, Shape: pgsql.MultiPoint{{X: 1, Y: 2}, {X: 3, Y: 4}}},
+		{Shape: pgsql.MultiLineString{{{X: 0, Y: 0}, {X: 1, Y: 1}}}},
+		{Shape: pgsql.MultiPolygon{{{{X: 0, Y: 0}, {X: 1, Y: 0}, {X: 1, Y: 1}, {X: 0, Y: 0}}}}},
+		{SRID: 4326, Shape: pgsql.GeometryCollection{pgsql.Point{X: 1, Y: 2}, pgsql.LineString{{X: 0, Y: 0}, {X: 1, Y: 1}}}},
+	}
+
+	for _, shape := range shapes {
+		v, err := shape.Value()
+		if err != nil {
+			t.Fatalf("Value of %v returned error %s", shape, err)
+		}
+
+		var scanned pgsql.Geometry
+		if err := scanned.Scan(v); err != nil {
+			t.Fatalf("Scan of %v returned error %s", v, err)
+		}
+
+		if !pgsql.Equal(scanned, shape) {
+			t.Errorf("Scan of %v = %v, expected %v", v, scanned, shape)
+		}
+	}
+
+	// SELECT 'POINT Z(1 2 3)'::geometry
+	if err := g.Scan("0101000080000000000000F03F00000000000000400000000000000840"); err == nil {
+		t.Errorf("Scan of a three dimensional point did not return an error")
+	}
+
+	if v, err := (pgsql.Geometry{}).Value(); err != nil || v != nil {
+		t.Errorf("Value of the zero Geometry = %v, expected NULL", v)
+	}
+}
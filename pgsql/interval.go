@@ -0,0 +1,348 @@
+package pgsql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Interval models a postgres interval. Months, days and microseconds are kept apart since
+// the length of a month or a day depends on the date the interval is added to
+type Interval struct {
+	Months       int32
+	Days         int32
+	Microseconds int64
+}
+
+// IntervalOf returns the interval holding the duration d as microseconds
+func IntervalOf(d time.Duration) Interval {
+	return Interval{Microseconds: d.Microseconds()}
+}
+
+// Duration converts the interval to a time.Duration counting a month as 30 days and a day
+// as 24 hours, as justify_interval does. The result is only exact when Months and Days are 0
+func (i Interval) Duration() time.Duration {
+	days := int64(i.Months)*30 + int64(i.Days)
+
+	return time.Duration(days)*24*time.Hour + time.Duration(i.Microseconds)*time.Microsecond
+}
+
+// String returns the interval in the postgres IntervalStyle, e.g. 1 year 2 mons -3 days +04:05:06.789.
+// Fields following a negative field carry an explicit sign, so the text reads the same in every IntervalStyle
+func (i Interval) String() string {
+	parts := []string{}
+	before := false
+
+	sign := func(n int64) string {
+		if n < 0 {
+			before = true
+			return "-"
+		}
+		if before {
+			return "+"
+		}
+		return ""
+	}
+
+	for _, p := range []struct {
+		n    int64
+		unit string
+	}{{int64(i.Months / 12), "year"}, {int64(i.Months % 12), "mon"}, {int64(i.Days), "day"}} {
+		if p.n == 0 {
+			continue
+		}
+
+		unit := p.unit + "s"
+		if p.n == 1 {
+			unit = p.unit
+		}
+
+		s := sign(p.n)
+		if p.n < 0 {
+			p.n = -p.n
+		}
+		parts = append(parts, fmt.Sprintf("%s%d %s", s, p.n, unit))
+	}
+
+	if i.Microseconds != 0 || len(parts) == 0 {
+		us := i.Microseconds
+		s := sign(us)
+		if us < 0 {
+			us = -us
+		}
+
+		clock := fmt.Sprintf("%s%02d:%02d:%02d", s, us/3600000000, us/60000000%60, us/1000000%60)
+		if frac := us % 1000000; frac != 0 {
+			clock += strings.TrimRight(fmt.Sprintf(".%06d", frac), "0")
+		}
+		parts = append(parts, clock)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// Scan implements sql.Scanner, accepting the output of every IntervalStyle. NULL scans as the zero interval
+func (i *Interval) Scan(src interface{}) error {
+	if src == nil {
+		*i = Interval{}
+		return nil
+	}
+
+	text, err := srcText(src)
+	if err != nil {
+		return err
+	}
+
+	v, err := ParseInterval(text)
+	if err != nil {
+		return err
+	}
+
+	*i = v
+
+	return nil
+}
+
+// Value implements driver.Valuer
+func (i Interval) Value() (driver.Value, error) {
+	return i.String(), nil
+}
+
+// intervalUnits maps the unit names of the postgres and postgres_verbose styles to months,
+// days or microseconds
+var intervalUnits = map[string]struct {
+	months, days, microseconds int64
+}{
+	"year":   {months: 12},
+	"mon":    {months: 1},
+	"month":  {months: 1},
+	"week":   {days: 7},
+	"day":    {days: 1},
+	"hour":   {microseconds: 3600000000},
+	"min":    {microseconds: 60000000},
+	"minute": {microseconds: 60000000},
+	"sec":    {microseconds: 1000000},
+	"second": {microseconds: 1000000},
+}
+
+// ParseInterval parses the text output of an interval in any IntervalStyle: postgres
+// (1 year 2 mons -3 days +04:05:06), postgres_verbose (@ 1 year 2 mons 3 days 4 hours ago),
+// sql_standard (+1-2 -3 +4:05:06) and iso_8601 (P1Y2M-3DT4H5M6S)
+func ParseInterval(text string) (Interval, error) {
+	text = strings.TrimSpace(text)
+
+	if strings.HasPrefix(text, "P") {
+		return parseISOInterval(text)
+	}
+
+	var i Interval
+	ago := false
+	if strings.HasPrefix(text, "@") {
+		text = strings.TrimSpace(strings.TrimPrefix(text, "@"))
+		if strings.HasSuffix(text, " ago") {
+			text, ago = strings.TrimSuffix(text, " ago"), true
+		}
+	}
+
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return i, fmt.Errorf("pgsql: invalid interval %q", text)
+	}
+
+	// sql_standard output with a single leading minus applies it to every field
+	negateAll := strings.HasPrefix(fields[0], "-")
+	for _, f := range fields[1:] {
+		if strings.HasPrefix(f, "+") || strings.HasPrefix(f, "-") || intervalUnit(f) {
+			negateAll = false
+		}
+	}
+
+	for n := 0; n < len(fields); n++ {
+		f := fields[n]
+		sign := int64(1)
+		if n > 0 && negateAll {
+			sign = -1
+		}
+
+		switch {
+		case n+1 < len(fields) && intervalUnit(fields[n+1]):
+			if err := i.add(f, strings.TrimSuffix(fields[n+1], "s")); err != nil {
+				return i, fmt.Errorf("pgsql: invalid interval %q: %s", text, err)
+			}
+			n++
+		case strings.Contains(f, ":"):
+			us, err := parseClock(f)
+			if err != nil {
+				return i, fmt.Errorf("pgsql: invalid interval %q: %s", text, err)
+			}
+			i.Microseconds += sign * us
+		case strings.Contains(strings.TrimLeft(f, "+-"), "-"):
+			neg := strings.HasPrefix(f, "-")
+			ym := strings.SplitN(strings.TrimLeft(f, "+-"), "-", 2)
+			y, err1 := strconv.ParseInt(ym[0], 10, 32)
+			m, err2 := strconv.ParseInt(ym[1], 10, 32)
+			if err1 != nil || err2 != nil {
+				return i, fmt.Errorf("pgsql: invalid interval %q", text)
+			}
+
+			months := y*12 + m
+			if neg {
+				months = -months
+			}
+			i.Months += int32(months)
+		default:
+			d, err := strconv.ParseInt(f, 10, 32)
+			if err != nil {
+				return i, fmt.Errorf("pgsql: invalid interval %q", text)
+			}
+			i.Days += int32(sign * d)
+		}
+	}
+
+	if ago {
+		i = Interval{Months: -i.Months, Days: -i.Days, Microseconds: -i.Microseconds}
+	}
+
+	return i, nil
+}
+
+// add adds number of unit, a key of intervalUnits, to the interval
+func (i *Interval) add(number string, unit string) error {
+	u := intervalUnits[unit]
+	if u.microseconds > 0 {
+		us, err := parseMicroseconds(number, u.microseconds)
+		if err != nil {
+			return err
+		}
+		i.Microseconds += us
+		return nil
+	}
+
+	v, err := strconv.ParseInt(number, 10, 32)
+	if err != nil {
+		return err
+	}
+	i.Months += int32(v * u.months)
+	i.Days += int32(v * u.days)
+
+	return nil
+}
+
+// intervalUnit reports whether word is a unit name, singular or plural, of the postgres styles
+func intervalUnit(word string) bool {
+	_, ok := intervalUnits[strings.TrimSuffix(word, "s")]
+
+	return ok
+}
+
+// parseMicroseconds multiplies a decimal number such as -6.789 by scale microseconds exactly
+func parseMicroseconds(number string, scale int64) (int64, error) {
+	neg := strings.HasPrefix(number, "-")
+	number = strings.TrimLeft(number, "+-")
+
+	whole, frac := number, ""
+	if dot := strings.Index(number, "."); dot >= 0 {
+		whole, frac = number[:dot], number[dot+1:]
+	}
+
+	if len(whole) == 0 {
+		whole = "0"
+	}
+
+	w, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	us := w * scale
+	for d := scale / 10; len(frac) > 0 && d > 0; d /= 10 {
+		if frac[0] < '0' || frac[0] > '9' {
+			return 0, fmt.Errorf("invalid number %s", number)
+		}
+		us += int64(frac[0]-'0') * d
+		frac = frac[1:]
+	}
+
+	if neg {
+		us = -us
+	}
+
+	return us, nil
+}
+
+// parseClock parses a signed [-+]h:mm[:ss[.ffffff]] time field as microseconds
+func parseClock(clock string) (int64, error) {
+	neg := strings.HasPrefix(clock, "-")
+	parts := strings.Split(strings.TrimLeft(clock, "+-"), ":")
+	if len(parts) > 3 {
+		return 0, fmt.Errorf("invalid time %s", clock)
+	}
+
+	var us int64
+	for n, scale := range []int64{3600000000, 60000000, 1000000}[:len(parts)] {
+		v, err := parseMicroseconds(parts[n], scale)
+		if err != nil {
+			return 0, err
+		}
+		us += v
+	}
+
+	if neg {
+		us = -us
+	}
+
+	return us, nil
+}
+
+// parseISOInterval parses the iso_8601 format with designators, e.g. P1Y2M3DT4H5M6.5S
+func parseISOInterval(text string) (Interval, error) {
+	var i Interval
+	inTime := false
+	number := ""
+
+	for _, c := range text[1:] {
+		switch {
+		case c == 'T':
+			inTime = true
+		case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+			number += string(c)
+		default:
+			if len(number) == 0 {
+				return i, fmt.Errorf("pgsql: invalid interval %q", text)
+			}
+
+			var unit string
+			switch {
+			case c == 'Y':
+				unit = "year"
+			case c == 'M' && !inTime:
+				unit = "mon"
+			case c == 'W':
+				unit = "week"
+			case c == 'D':
+				unit = "day"
+			case c == 'H':
+				unit = "hour"
+			case c == 'M':
+				unit = "min"
+			case c == 'S':
+				unit = "sec"
+			default:
+				return i, fmt.Errorf("pgsql: invalid interval %q", text)
+			}
+
+			if err := i.add(number, unit); err != nil {
+				return i, fmt.Errorf("pgsql: invalid interval %q: %s", text, err)
+			}
+			number = ""
+		}
+	}
+
+	if len(number) > 0 {
+		return i, fmt.Errorf("pgsql: invalid interval %q", text)
+	}
+
+	return i, nil
+}
@@ -0,0 +1,90 @@
+package pgsql_test
+
+import (
+	"testing"
+	"time"
+
+	"pggen/pgsql"
+)
+
+func TestParseInterval(t *testing.T) {
+	mixed := pgsql.Interval{Months: 14, Days: -3, Microseconds: 4*3600000000 + 5*60000000 + 6789000}
+	negative := pgsql.Interval{Months: -14, Days: -3, Microseconds: -(4*3600000000 + 5*60000000 + 6789000)}
+
+	styles := []struct {
+		text     string
+		interval pgsql.Interval
+	}{
+		// postgres
+		{"1 year 2 mons -3 days +04:05:06.789", mixed},
+		{"-1 years -2 mons -3 days -04:05:06.789", negative},
+		{"00:00:00", pgsql.Interval{}},
+		{"3 days", pgsql.Interval{Days: 3}},
+		{"-00:00:00.5", pgsql.Interval{Microseconds: -500000}},
+		// postgres_verbose
+		{"@ 1 year 2 mons -3 days 4 hours 5 mins 6.789 secs", mixed},
+		{"@ 1 year 2 mons 3 days 4 hours 5 mins 6.789 secs ago", negative},
+		{"@ 0", pgsql.Interval{}},
+		// sql_standard
+		{"+1-2 -3 +4:05:06.789", mixed},
+		{"-1-2 -3 -4:05:06.789", negative},
+		{"1-2", pgsql.Interval{Months: 14}},
+		{"-3 4:05:06.789", pgsql.Interval{Days: -3, Microseconds: -(4*3600000000 + 5*60000000 + 6789000)}},
+		{"0", pgsql.Interval{}},
+		// iso_8601
+		{"P1Y2M-3DT4H5M6.789S", mixed},
+		{"P-1Y-2M-3DT-4H-5M-6.789S", negative},
+		{"PT0S", pgsql.Interval{}},
+		{"P1W", pgsql.Interval{Days: 7}},
+	}
+
+	for _, s := range styles {
+		i, err := pgsql.ParseInterval(s.text)
+		if err != nil {
+			t.Errorf("ParseInterval(%q) returned error %s", s.text, err)
+			continue
+		}
+
+		if i != s.interval {
+			t.Errorf("ParseInterval(%q) = %+v, expected %+v", s.text, i, s.interval)
+		}
+	}
+
+	for _, text := range []string{"", "P1X", "1 fortnight", "1:2:3:4"} {
+		if _, err := pgsql.ParseInterval(text); err == nil {
+			t.Errorf("ParseInterval(%q) did not return an error", text)
+		}
+	}
+}
+
+func TestIntervalValue(t *testing.T) {
+	intervals := map[string]pgsql.Interval{
+		"00:00:00":                            {},
+		"1 year 2 mons -3 days +04:05:06.789": {Months: 14, Days: -3, Microseconds: 14706789000},
+		"-1 mons +1 day":                      {Months: -1, Days: 1},
+		"-00:00:00.000001":                    {Microseconds: -1},
+	}
+
+	for text, i := range intervals {
+		v, err := i.Value()
+		if err != nil || v != text {
+			t.Errorf("Value of %+v = %v, expected %s", i, v, text)
+		}
+
+		var scanned pgsql.Interval
+		if err := scanned.Scan([]byte(text)); err != nil || scanned != i {
+			t.Errorf("Scan(%q) = %+v, expected %+v", text, scanned, i)
+		}
+	}
+}
+
+func TestIntervalDuration(t *testing.T) {
+	i := pgsql.Interval{Months: 1, Days: 1, Microseconds: 1500000}
+	if d := i.Duration(); d != 31*24*time.Hour+1500*time.Millisecond {
+		t.Errorf("Duration = %s", d)
+	}
+
+	if i := pgsql.IntervalOf(90 * time.Minute); i.Microseconds != 5400000000 || i.Duration() != 90*time.Minute {
+		t.Errorf("IntervalOf(90m) = %+v", i)
+	}
+}
@@ -0,0 +1,165 @@
+package pgsql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"sync/atomic"
+)
+
+// DefaultFetchSize is the number of rows fetched at a time from a cursor when WithCursor is given 0
+const DefaultFetchSize = 1000
+
+// IterOption configures the generated Iterate and All methods
+type IterOption func(*iterOptions)
+
+type iterOptions struct {
+	orderBy   string
+	cursor    bool
+	fetchSize int
+}
+
+// WithOrderBy orders the rows by an sql order by list such as "created desc, id", it must not hold user input
+func WithOrderBy(orderBy string) IterOption {
+	return func(o *iterOptions) {
+		o.orderBy = orderBy
+	}
+}
+
+// WithCursor reads the rows through a server side cursor, fetchSize rows at a time, 0 for
+// DefaultFetchSize, so neither the server nor the driver buffers the whole result. The cursor is
+// declared in the transaction of a PgSQL passed to a RunInTx closure, otherwise in a read only
+// transaction of its own that is not retried
+func WithCursor(fetchSize int) IterOption {
+	return func(o *iterOptions) {
+		o.cursor = true
+		o.fetchSize = fetchSize
+	}
+}
+
+// errStop is returned to Iterate by the callback of All when the loop over the iterator stops early
+var errStop = errors.New("pgsql: iteration stopped")
+
+// cursors numbers the cursors declared by Iterate
+var cursors atomic.Int64
+
+// Iterate runs the statement on table, calling fn with each row scanned by scan as it is read. It
+// stops at the first error of fn, which is returned unchanged, other errors are classified by Classify
+func Iterate[T any](ctx context.Context, pg *PgSQL, table string, sql string, args []interface{}, scan func(Row) (*T, error), fn func(T) error, opts ...IterOption) error {
+	o := iterOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if len(o.orderBy) > 0 {
+		sql += " order by " + o.orderBy
+	}
+
+	each := func(rows Rows) (int, error) {
+		n := 0
+		for rows.Next() {
+			item, err := scan(rows)
+			if err != nil {
+				return n, Classify(err, table)
+			}
+			n++
+
+			if err := fn(*item); err != nil {
+				return n, err
+			}
+		}
+
+		return n, Classify(rows.Err(), table)
+	}
+
+	if !o.cursor {
+		rows, closeRows, err := pg.query(ctx, sql, args)
+		if err != nil {
+			return Classify(err, table)
+		}
+		defer closeRows()
+
+		_, err = each(rows)
+		return err
+	}
+
+	if o.fetchSize <= 0 {
+		o.fetchSize = DefaultFetchSize
+	}
+
+	fetch := func(ctx context.Context, tx *PgSQL) error {
+		cursor := fmt.Sprintf("pggen_cursor_%d", cursors.Add(1))
+		if err := tx.exec(ctx, "declare "+cursor+" no scroll cursor for "+sql, args); err != nil {
+			return Classify(err, table)
+		}
+		defer tx.exec(context.Background(), "close "+cursor, nil)
+
+		for {
+			rows, closeRows, err := tx.query(ctx, fmt.Sprintf("fetch forward %d from %s", o.fetchSize, cursor), nil)
+			if err != nil {
+				return Classify(err, table)
+			}
+
+			n, err := each(rows)
+			closeRows()
+			if err != nil || n < o.fetchSize {
+				return err
+			}
+		}
+	}
+
+	if pg.tx != nil {
+		return fetch(ctx, pg)
+	}
+
+	_, err := pg.RunInTx(ctx, &TxOptions{ReadOnly: true, Retries: -1}, fetch)
+	return err
+}
+
+// All returns an iterator over the rows of the statement on table scanned by scan, as Iterate.
+// An error ends the iteration, yielded with the zero T
+func All[T any](ctx context.Context, pg *PgSQL, table string, sql string, args []interface{}, scan func(Row) (*T, error), opts ...IterOption) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		err := Iterate(ctx, pg, table, sql, args, scan, func(item T) error {
+			if !yield(item, nil) {
+				return errStop
+			}
+			return nil
+		}, opts...)
+
+		if err != nil && !errors.Is(err, errStop) {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+// query runs a statement returning rows on the driver of pg, closeRows releases them
+func (pg *PgSQL) query(ctx context.Context, sql string, args []interface{}) (Rows, func(), error) {
+	if conn := pg.Pgx(); conn != nil {
+		rows, err := conn.Query(ctx, sql, args...)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return rows, rows.Close, nil
+	}
+
+	rows, err := pg.Conn().QueryContext(ctx, sql, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rows, func() { rows.Close() }, nil
+}
+
+// exec runs a statement on the driver of pg
+func (pg *PgSQL) exec(ctx context.Context, sql string, args []interface{}) error {
+	if conn := pg.Pgx(); conn != nil {
+		_, err := conn.Exec(ctx, sql, args...)
+		return err
+	}
+
+	_, err := pg.Conn().ExecContext(ctx, sql, args...)
+	return err
+}
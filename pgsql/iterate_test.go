@@ -0,0 +1,66 @@
+package pgsql_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"pggen/pgsql"
+)
+
+func scanIntPtr(row pgsql.Row) (*int, error) {
+	n, err := scanInt(row)
+	return &n, err
+}
+
+func TestIterate(t *testing.T) {
+	pg, d := newStmtPgSQL(t)
+	ctx := context.Background()
+
+	calls := 0
+	err := pgsql.Iterate(ctx, pg, "public.member", "select 1", []interface{}{7}, scanIntPtr, func(n int) error {
+		calls++
+		return nil
+	}, pgsql.WithOrderBy("n"))
+
+	if err != nil || calls != 1 || d.statements[len(d.statements)-1] != "select 1 order by n" {
+		t.Errorf("Iterate = %v after %d calls of %q, expected 1", err, calls, d.statements)
+	}
+
+	stop := errors.New("stop")
+	if err := pgsql.Iterate(ctx, pg, "public.member", "select 1", nil, scanIntPtr, func(n int) error {
+		return stop
+	}); err != stop {
+		t.Errorf("Iterate with fn failing = %v, expected its error", err)
+	}
+
+	d.statements, d.fetches, calls = nil, 2, 0
+	err = pgsql.Iterate(ctx, pg, "public.member", "select 1", []interface{}{7}, scanIntPtr, func(n int) error {
+		calls++
+		return nil
+	}, pgsql.WithCursor(1))
+
+	log := strings.Join(d.statements, "; ")
+	if err != nil || calls != 2 || !strings.HasPrefix(log, "declare pggen_cursor_") || !strings.Contains(log, " no scroll cursor for select 1; fetch forward 1 from pggen_cursor_") ||
+		!strings.Contains(log, "; close pggen_cursor_") {
+		t.Errorf("Iterate with a cursor = %v after %d calls of %s, expected 2", err, calls, log)
+	}
+}
+
+func TestAll(t *testing.T) {
+	pg, _ := newStmtPgSQL(t)
+
+	calls := 0
+	for n, err := range pgsql.All(context.Background(), pg, "public.member", "select 1", nil, scanIntPtr) {
+		if err != nil || n != 1 {
+			t.Errorf("All yielded %d, %v, expected 1", n, err)
+		}
+		calls++
+		break
+	}
+
+	if calls != 1 {
+		t.Errorf("All yielded %d rows, expected 1", calls)
+	}
+}
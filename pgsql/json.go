@@ -0,0 +1,61 @@
+package pgsql
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// JSON holds the value of a json or jsonb column decoded into V. Columns map to JSON[any]
+// unless annotated with the go type to decode into, e.g. @pggen:json=github.com/x/y.Settings
+type JSON[T any] struct {
+	V T
+}
+
+// MustJSON decodes text into a JSON[T], panicking if it is not valid json for T. It is meant
+// for literals such as generated test values
+func MustJSON[T any](text string) JSON[T] {
+	var j JSON[T]
+	if err := j.Scan(text); err != nil {
+		panic(err)
+	}
+
+	return j
+}
+
+// Scan implements sql.Scanner. NULL scans as the zero value
+func (j *JSON[T]) Scan(src interface{}) error {
+	var v T
+	if src == nil {
+		j.V = v
+		return nil
+	}
+
+	text, err := srcText(src)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal([]byte(text), &v); err != nil {
+		return fmt.Errorf("pgsql: %s", err)
+	}
+
+	j.V = v
+
+	return nil
+}
+
+// Value implements driver.Valuer. A V that encodes to the json null, such as a nil pointer or
+// map, is stored as NULL
+func (j JSON[T]) Value() (driver.Value, error) {
+	b, err := json.Marshal(j.V)
+	if err != nil {
+		return nil, err
+	}
+
+	if string(b) == "null" {
+		return nil, nil
+	}
+
+	return string(b), nil
+}
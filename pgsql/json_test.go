@@ -0,0 +1,59 @@
+package pgsql_test
+
+import (
+	"testing"
+	"time"
+
+	"pggen/pgsql"
+)
+
+type settings struct {
+	Theme string
+	Sizes []int
+}
+
+func TestJSON(t *testing.T) {
+	var j pgsql.JSON[settings]
+	if err := j.Scan([]byte(`{"Sizes": [1, 2],   "Theme": "dark"}`)); err != nil {
+		t.Fatalf("Scan returned error %s", err)
+	}
+
+	if !pgsql.Equal(j, pgsql.JSON[settings]{V: settings{Theme: "dark", Sizes: []int{1, 2}}}) {
+		t.Errorf("Scan = %+v", j)
+	}
+
+	if v, err := j.Value(); err != nil || v != `{"Theme":"dark","Sizes":[1,2]}` {
+		t.Errorf("Value = %v", v)
+	}
+
+	if v, err := (pgsql.JSON[*settings]{}).Value(); err != nil || v != nil {
+		t.Errorf("Value of a nil pointer = %v, expected NULL", v)
+	}
+
+	a := pgsql.MustJSON[any](`{"ID": 1, "Name": "x"}`)
+	b := pgsql.MustJSON[any](`{"Name":"x","ID":1}`)
+	if !pgsql.Equal(a, b) {
+		t.Errorf("json values differing in whitespace and key order are not Equal")
+	}
+
+	if pgsql.Equal(a, pgsql.MustJSON[any](`{"ID": 2, "Name": "x"}`)) {
+		t.Errorf("json values with different content are Equal")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	utc := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	local := utc.In(time.FixedZone("", 3600))
+
+	if !pgsql.Equal(utc, local) || !pgsql.Equal([]time.Time{utc}, []time.Time{local}) {
+		t.Errorf("equal times in different locations are not Equal")
+	}
+
+	if !pgsql.Equal([]string(nil), []string{}) {
+		t.Errorf("nil and empty slices are not Equal")
+	}
+
+	if pgsql.Equal(1, int64(1)) || pgsql.Equal("a", "b") {
+		t.Errorf("different values are Equal")
+	}
+}
@@ -0,0 +1,140 @@
+package pgsql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// Inet wraps a netip.Addr for inet columns, the zero value is NULL. An inet holding a netmask
+// shorter than its address, e.g. 10.1.2.3/24, does not fit an Addr and fails to scan, such
+// columns can be annotated with @pggen:type=pggen/pgsql.Cidr
+type Inet struct {
+	netip.Addr
+}
+
+// Scan implements sql.Scanner
+func (i *Inet) Scan(src interface{}) error {
+	if src == nil {
+		*i = Inet{}
+		return nil
+	}
+
+	text, err := srcText(src)
+	if err != nil {
+		return err
+	}
+
+	if slash := strings.Index(text, "/"); slash >= 0 {
+		p, err := netip.ParsePrefix(text)
+		if err != nil {
+			return fmt.Errorf("pgsql: %s", err)
+		}
+
+		if p.Bits() != p.Addr().BitLen() {
+			return fmt.Errorf("pgsql: inet %s has a netmask and cannot be scanned into an Inet", text)
+		}
+		text = text[:slash]
+	}
+
+	addr, err := netip.ParseAddr(text)
+	if err != nil {
+		return fmt.Errorf("pgsql: %s", err)
+	}
+
+	i.Addr = addr
+
+	return nil
+}
+
+// Value implements driver.Valuer
+func (i Inet) Value() (driver.Value, error) {
+	if !i.IsValid() {
+		return nil, nil
+	}
+
+	return i.String(), nil
+}
+
+// Cidr wraps a netip.Prefix for cidr columns, the zero value is NULL
+type Cidr struct {
+	netip.Prefix
+}
+
+// Scan implements sql.Scanner. An inet value without a netmask scans as a single address prefix
+func (c *Cidr) Scan(src interface{}) error {
+	if src == nil {
+		*c = Cidr{}
+		return nil
+	}
+
+	text, err := srcText(src)
+	if err != nil {
+		return err
+	}
+
+	if !strings.Contains(text, "/") {
+		addr, err := netip.ParseAddr(text)
+		if err != nil {
+			return fmt.Errorf("pgsql: %s", err)
+		}
+		c.Prefix = netip.PrefixFrom(addr, addr.BitLen())
+		return nil
+	}
+
+	p, err := netip.ParsePrefix(text)
+	if err != nil {
+		return fmt.Errorf("pgsql: %s", err)
+	}
+
+	c.Prefix = p
+
+	return nil
+}
+
+// Value implements driver.Valuer
+func (c Cidr) Value() (driver.Value, error) {
+	if !c.IsValid() {
+		return nil, nil
+	}
+
+	return c.String(), nil
+}
+
+// MacAddr wraps a net.HardwareAddr for macaddr and macaddr8 columns, the zero value is NULL
+type MacAddr struct {
+	net.HardwareAddr
+}
+
+// Scan implements sql.Scanner
+func (m *MacAddr) Scan(src interface{}) error {
+	if src == nil {
+		*m = MacAddr{}
+		return nil
+	}
+
+	text, err := srcText(src)
+	if err != nil {
+		return err
+	}
+
+	hw, err := net.ParseMAC(text)
+	if err != nil {
+		return fmt.Errorf("pgsql: %s", err)
+	}
+
+	m.HardwareAddr = hw
+
+	return nil
+}
+
+// Value implements driver.Valuer
+func (m MacAddr) Value() (driver.Value, error) {
+	if m.HardwareAddr == nil {
+		return nil, nil
+	}
+
+	return m.String(), nil
+}
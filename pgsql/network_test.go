@@ -0,0 +1,65 @@
+package pgsql_test
+
+import (
+	"net/netip"
+	"testing"
+
+	"pggen/pgsql"
+)
+
+func TestInet(t *testing.T) {
+	addrs := map[string]string{
+		"192.0.2.1":       "192.0.2.1",
+		"192.0.2.1/32":    "192.0.2.1",
+		"2001:db8::1":     "2001:db8::1",
+		"2001:db8::1/128": "2001:db8::1",
+	}
+
+	for text, addr := range addrs {
+		var i pgsql.Inet
+		if err := i.Scan([]byte(text)); err != nil {
+			t.Fatalf("Scan(%q) returned error %s", text, err)
+		}
+
+		if i.Addr != netip.MustParseAddr(addr) {
+			t.Errorf("Scan(%q) = %s, expected %s", text, i.Addr, addr)
+		}
+
+		if v, err := i.Value(); err != nil || v != addr {
+			t.Errorf("Value after Scan(%q) = %v, expected %s", text, v, addr)
+		}
+	}
+
+	var i pgsql.Inet
+	if err := i.Scan("10.1.2.3/24"); err == nil {
+		t.Errorf("Scan of an inet with a netmask did not return an error")
+	}
+
+	if v, err := (pgsql.Inet{}).Value(); err != nil || v != nil {
+		t.Errorf("Value of the zero Inet = %v, expected NULL", v)
+	}
+}
+
+func TestCidr(t *testing.T) {
+	var c pgsql.Cidr
+	if err := c.Scan([]byte("10.1.0.0/16")); err != nil || c.Prefix != netip.MustParsePrefix("10.1.0.0/16") {
+		t.Errorf("Scan = %v, %v", c, err)
+	}
+
+	if v, err := c.Value(); err != nil || v != "10.1.0.0/16" {
+		t.Errorf("Value = %v", v)
+	}
+}
+
+func TestMacAddr(t *testing.T) {
+	for _, text := range []string{"08:00:2b:01:02:03", "08:00:2b:01:02:03:04:05"} {
+		var m pgsql.MacAddr
+		if err := m.Scan([]byte(text)); err != nil {
+			t.Fatalf("Scan(%q) returned error %s", text, err)
+		}
+
+		if v, err := m.Value(); err != nil || v != text {
+			t.Errorf("Value after Scan(%q) = %v", text, v)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package pgsql
+
+import (
+	"database/sql/driver"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Numeric holds the exact decimal text of a numeric value, such as 12.5 or NaN, which
+// float64 cannot represent without loss. The zero value is 0. A NULL scans as the zero value,
+// NullNumeric keeps NULL apart from 0
+type Numeric string
+
+// Scan implements sql.Scanner. Trailing zeros of the fraction are dropped so that equal
+// values scanned from columns of different scale compare equal
+func (n *Numeric) Scan(src interface{}) error {
+	if src == nil {
+		*n = ""
+		return nil
+	}
+
+	text, err := srcText(src)
+	if err != nil {
+		return err
+	}
+
+	if strings.Contains(text, ".") {
+		text = strings.TrimRight(strings.TrimRight(text, "0"), ".")
+	}
+
+	if text == "-0" {
+		text = "0"
+	}
+
+	*n = Numeric(text)
+
+	return nil
+}
+
+// Value implements driver.Valuer, binding the zero Numeric as 0
+func (n Numeric) Value() (driver.Value, error) {
+	if n == "" {
+		return "0", nil
+	}
+
+	return string(n), nil
+}
+
+// Rat returns the value as a big.Rat, ok is false for NaN and infinities
+func (n Numeric) Rat() (*big.Rat, bool) {
+	if n == "" {
+		return new(big.Rat), true
+	}
+
+	return new(big.Rat).SetString(string(n))
+}
+
+// Float64 returns the nearest float64 to the value
+func (n Numeric) Float64() (float64, error) {
+	if n == "" {
+		return 0, nil
+	}
+
+	return strconv.ParseFloat(string(n), 64)
+}
+
+// NullNumeric is a Numeric that may be NULL, as sql.NullString is a string that may be NULL.
+// Generated code uses it for nullable numeric columns
+type NullNumeric struct {
+	Numeric Numeric
+	Valid   bool
+}
+
+// Scan implements sql.Scanner
+func (n *NullNumeric) Scan(src interface{}) error {
+	if src == nil {
+		*n = NullNumeric{}
+		return nil
+	}
+
+	n.Valid = true
+
+	return n.Numeric.Scan(src)
+}
+
+// Value implements driver.Valuer, binding an invalid NullNumeric as NULL
+func (n NullNumeric) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+
+	return n.Numeric.Value()
+}
@@ -0,0 +1,190 @@
+package pgsql
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Option configures the connection pool opened by NewPgSQL
+type Option func(*options)
+
+type options struct {
+	maxOpen       int
+	maxIdle       int
+	maxIdleSet    bool
+	maxLifetime   time.Duration
+	maxIdleTime   time.Duration
+	startupCheck  time.Duration
+	runtimeParams map[string]string
+	noStmtCache   bool
+	driver        Driver
+}
+
+// WithMaxOpenConns limits the number of open connections, see sql.DB.SetMaxOpenConns
+func WithMaxOpenConns(n int) Option {
+	return func(o *options) {
+		o.maxOpen = n
+	}
+}
+
+// WithMaxIdleConns limits the number of idle connections kept in the pool, see sql.DB.SetMaxIdleConns.
+// With DriverPgx it sets the MinConns of the pool, which closes the idle connections above it once
+// they have been idle for the MaxConnIdleTime of pgx, see WithConnMaxIdleTime
+func WithMaxIdleConns(n int) Option {
+	return func(o *options) {
+		o.maxIdle = n
+		o.maxIdleSet = true
+	}
+}
+
+// WithConnMaxLifetime closes connections once they have been open for d, see sql.DB.SetConnMaxLifetime
+func WithConnMaxLifetime(d time.Duration) Option {
+	return func(o *options) {
+		o.maxLifetime = d
+	}
+}
+
+// WithConnMaxIdleTime closes connections once they have been idle for d, see sql.DB.SetConnMaxIdleTime
+func WithConnMaxIdleTime(d time.Duration) Option {
+	return func(o *options) {
+		o.maxIdleTime = d
+	}
+}
+
+// WithStatementTimeout sets the statement_timeout of every connection, postgres cancels
+// statements running longer than d. It is rounded up to whole milliseconds, as a statement_timeout
+// of 0 disables the timeout
+func WithStatementTimeout(d time.Duration) Option {
+	ms := d.Milliseconds()
+	if d%time.Millisecond > 0 {
+		ms++
+	}
+
+	return WithRuntimeParam("statement_timeout", fmt.Sprintf("%d", ms))
+}
+
+// WithApplicationName sets the application_name of every connection, shown in pg_stat_activity
+func WithApplicationName(name string) Option {
+	return WithRuntimeParam("application_name", name)
+}
+
+// WithRuntimeParam sets a run-time parameter, e.g. search_path, when connections are opened
+func WithRuntimeParam(name string, value string) Option {
+	return func(o *options) {
+		if o.runtimeParams == nil {
+			o.runtimeParams = map[string]string{}
+		}
+		o.runtimeParams[name] = value
+	}
+}
+
+// WithStartupCheck makes NewPgSQL ping the database once, failing when it cannot connect within timeout
+func WithStartupCheck(timeout time.Duration) Option {
+	return func(o *options) {
+		o.startupCheck = timeout
+	}
+}
+
+// WithStatementCache enables or disables the cache of prepared statements used by Prepared, enabled
+// by default. Disable it behind a pooler, such as pgbouncer in transaction mode, that does not keep
+// prepared statements on the server connection. With DriverPgx it selects the statement cache of pgx
+func WithStatementCache(enabled bool) Option {
+	return func(o *options) {
+		o.noStmtCache = !enabled
+	}
+}
+
+// configure applies the pool options to pg
+func (o *options) configure(pg *PgSQL) {
+	if o.maxOpen > 0 {
+		pg.Db.SetMaxOpenConns(o.maxOpen)
+	}
+	if o.maxIdleSet {
+		pg.Db.SetMaxIdleConns(o.maxIdle)
+	}
+	if o.maxLifetime > 0 {
+		pg.Db.SetConnMaxLifetime(o.maxLifetime)
+	}
+	if o.maxIdleTime > 0 {
+		pg.Db.SetConnMaxIdleTime(o.maxIdleTime)
+	}
+}
+
+// configurePgx applies the pool, run-time parameter and statement cache options to a pgx config
+func (o *options) configurePgx(config *pgxpool.Config) {
+	if o.maxOpen > 0 {
+		config.MaxConns = int32(o.maxOpen)
+	}
+	if o.maxLifetime > 0 {
+		config.MaxConnLifetime = o.maxLifetime
+	}
+	if o.maxIdleTime > 0 {
+		config.MaxConnIdleTime = o.maxIdleTime
+	}
+	if o.maxIdleSet && o.maxIdle > 0 {
+		config.MinConns = int32(o.maxIdle)
+		if config.MinConns > config.MaxConns {
+			config.MinConns = config.MaxConns
+		}
+	}
+	for name, value := range o.runtimeParams {
+		config.ConnConfig.RuntimeParams[name] = value
+	}
+	if o.noStmtCache {
+		config.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeExec
+	}
+}
+
+// check pings the database when a startup check was requested
+func (o *options) check(pg *PgSQL) error {
+	if o.startupCheck <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), o.startupCheck)
+	defer cancel()
+
+	if err := pg.Db.PingContext(ctx); err != nil {
+		return fmt.Errorf("pgsql: startup check: %w", err)
+	}
+
+	return nil
+}
+
+// withRuntimeParams adds run-time parameters to a connection string in either the
+// postgres:// url or the keyword=value form
+func withRuntimeParams(connectionString string, params map[string]string) (string, error) {
+	if len(params) == 0 {
+		return connectionString, nil
+	}
+
+	if strings.HasPrefix(connectionString, "postgres://") || strings.HasPrefix(connectionString, "postgresql://") {
+		u, err := url.Parse(connectionString)
+		if err != nil {
+			return "", fmt.Errorf("pgsql: %w", err)
+		}
+
+		q := u.Query()
+		for name, value := range params {
+			q.Set(name, value)
+		}
+		u.RawQuery = q.Encode()
+
+		return u.String(), nil
+	}
+
+	var b strings.Builder
+	b.WriteString(connectionString)
+	for name, value := range params {
+		value = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+		fmt.Fprintf(&b, " %s='%s'", name, value)
+	}
+
+	return b.String(), nil
+}
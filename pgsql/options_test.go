@@ -0,0 +1,123 @@
+package pgsql_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"pggen/pgsql"
+)
+
+func TestNewPgSQLOptions(t *testing.T) {
+	pg, err := pgsql.NewPgSQL("host=127.0.0.1 port=1 dbname=none sslmode=disable",
+		pgsql.WithMaxOpenConns(7), pgsql.WithMaxIdleConns(2), pgsql.WithConnMaxLifetime(time.Minute),
+		pgsql.WithConnMaxIdleTime(time.Second), pgsql.WithStatementTimeout(5*time.Second),
+		pgsql.WithApplicationName("it's pggen"))
+	if err != nil {
+		t.Fatalf("NewPgSQL returned error %s", err)
+	}
+	defer pg.Db.Close()
+
+	if n := pg.Db.Stats().MaxOpenConnections; n != 7 {
+		t.Errorf("MaxOpenConnections = %d, expected 7", n)
+	}
+
+	for _, dsn := range []string{"host=127.0.0.1 port=1 dbname=none sslmode=disable", "postgres://127.0.0.1:1/none?sslmode=disable"} {
+		pg, err := pgsql.NewPgSQL(dsn, pgsql.WithApplicationName("pggen"), pgsql.WithStartupCheck(time.Second))
+		if err == nil {
+			pg.Db.Close()
+			t.Errorf("NewPgSQL(%q) with a startup check of an unreachable server returned no error", dsn)
+		}
+	}
+
+	if _, err := pgsql.NewPgSQL("postgres://%zz", pgsql.WithApplicationName("pggen")); err == nil {
+		t.Errorf("NewPgSQL of an invalid url returned no error")
+	}
+}
+
+func TestNewPgSQLPgx(t *testing.T) {
+	pg, err := pgsql.NewPgSQL("host=127.0.0.1 port=1 dbname=none sslmode=disable", pgsql.WithDriver(pgsql.DriverPgx),
+		pgsql.WithMaxOpenConns(7), pgsql.WithMaxIdleConns(2), pgsql.WithConnMaxLifetime(time.Minute),
+		pgsql.WithApplicationName("pggen"), pgsql.WithStatementTimeout(1500*time.Microsecond), pgsql.WithStatementCache(false))
+	if err != nil {
+		t.Fatalf("NewPgSQL returned error %s", err)
+	}
+	defer pg.Close()
+
+	if pg.Pool == nil || pg.Db == nil || pg.Pgx() == nil {
+		t.Fatalf("NewPgSQL with DriverPgx did not open a pgx pool")
+	}
+
+	config := pg.Pool.Config()
+	if config.MaxConns != 7 || config.MinConns != 2 || config.MaxConnLifetime != time.Minute {
+		t.Errorf("pgx pool MaxConns = %d, MinConns = %d, MaxConnLifetime = %s, expected 7, 2 and 1m",
+			config.MaxConns, config.MinConns, config.MaxConnLifetime)
+	}
+
+	if timeout := config.ConnConfig.RuntimeParams["statement_timeout"]; timeout != "2" {
+		t.Errorf("pgx statement_timeout of 1.5ms = %q, expected 2", timeout)
+	}
+
+	if name := config.ConnConfig.RuntimeParams["application_name"]; name != "pggen" {
+		t.Errorf("pgx application_name = %q, expected pggen", name)
+	}
+
+	if mode := config.ConnConfig.DefaultQueryExecMode; mode != pgx.QueryExecModeExec {
+		t.Errorf("pgx DefaultQueryExecMode without the statement cache = %s", mode)
+	}
+
+	if _, err := pgsql.NewPgSQL("host=127.0.0.1 port=1 dbname=none sslmode=disable", pgsql.WithDriver(pgsql.DriverPgx),
+		pgsql.WithStartupCheck(time.Second)); err == nil {
+		t.Errorf("NewPgSQL with DriverPgx and a startup check of an unreachable server returned no error")
+	}
+
+	sub, err := pgsql.NewPgSQL("host=127.0.0.1 port=1 dbname=none sslmode=disable", pgsql.WithDriver(pgsql.DriverPgx),
+		pgsql.WithStatementTimeout(time.Microsecond))
+	if err != nil {
+		t.Fatalf("NewPgSQL returned error %s", err)
+	}
+	defer sub.Close()
+
+	if timeout := sub.Pool.Config().ConnConfig.RuntimeParams["statement_timeout"]; timeout != "1" {
+		t.Errorf("statement_timeout of 1µs = %q, expected 1 rather than 0, which disables it", timeout)
+	}
+
+	if plain, err := pgsql.NewPgSQL("host=127.0.0.1 port=1 dbname=none sslmode=disable"); err != nil || plain.Pgx() != nil {
+		t.Errorf("NewPgSQL without DriverPgx = %v, Pgx() = %v, expected no pgx pool", err, plain.Pgx())
+	}
+
+	if pg.PgxStore() != pg.Pgx() {
+		t.Errorf("PgxStore with DriverPgx = %v, expected the pool", pg.PgxStore())
+	}
+}
+
+func TestPgxStoreWrongDriver(t *testing.T) {
+	pg, err := pgsql.NewPgSQL("host=127.0.0.1 port=1 dbname=none sslmode=disable")
+	if err != nil {
+		t.Fatalf("NewPgSQL returned error %s", err)
+	}
+	defer pg.Close()
+
+	ctx := context.Background()
+	conn := pg.PgxStore()
+
+	if _, err := conn.Exec(ctx, "delete from member"); !errors.Is(err, pgsql.ErrWrongDriver) {
+		t.Errorf("Exec without DriverPgx = %v, expected ErrWrongDriver", err)
+	}
+
+	if _, err := conn.Query(ctx, "select 1"); !errors.Is(err, pgsql.ErrWrongDriver) {
+		t.Errorf("Query without DriverPgx = %v, expected ErrWrongDriver", err)
+	}
+
+	var n int
+	if err := conn.QueryRow(ctx, "select 1").Scan(&n); !errors.Is(err, pgsql.ErrWrongDriver) {
+		t.Errorf("QueryRow without DriverPgx = %v, expected ErrWrongDriver", err)
+	}
+
+	if err := pgsql.Classify(conn.QueryRow(ctx, "select 1").Scan(&n), "public.member"); !errors.Is(err, pgsql.ErrWrongDriver) {
+		t.Errorf("Classify of ErrWrongDriver = %v", err)
+	}
+}
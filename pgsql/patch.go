@@ -0,0 +1,72 @@
+package pgsql
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrEmptyPatch is returned by generated Patch methods given a patch that sets no column
+var ErrEmptyPatch = errors.New("pgsql: patch sets no column")
+
+// Optional is a field of a generated patch, the column is updated only when Set.
+// The zero Optional leaves the column unchanged
+type Optional[T any] struct {
+	Value T
+	Set   bool
+}
+
+// Set returns an Optional setting its column to v, e.g. MemberPatch{Email: pgsql.Set("a@example.com")}
+func Set[T any](v T) Optional[T] {
+	return Optional[T]{Value: v, Set: true}
+}
+
+// Assignments is the set list of an update, built by generated Patch methods from the fields set
+type Assignments struct {
+	columns    []string
+	args       []interface{}
+	increments []string
+}
+
+// Add assigns arg to column
+func (a *Assignments) Add(column string, arg interface{}) {
+	a.columns = append(a.columns, column)
+	a.args = append(a.args, arg)
+}
+
+// Increment assigns column its value plus one, e.g. the version column of the row. It takes no
+// argument and is not counted by Len
+func (a *Assignments) Increment(column string) {
+	a.increments = append(a.increments, column)
+}
+
+// Len returns the number of columns assigned an argument
+func (a *Assignments) Len() int {
+	return len(a.columns)
+}
+
+// Clause returns the set list, "a = $1, b = $2, version = version + 1", with its placeholders numbered from $1
+func (a *Assignments) Clause() string {
+	var b strings.Builder
+
+	for i, column := range a.columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%s = $%d", column, i+1)
+	}
+
+	for _, column := range a.increments {
+		if b.Len() > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%s = %s + 1", column, column)
+	}
+
+	return b.String()
+}
+
+// Args returns the arguments of the set list
+func (a *Assignments) Args() []interface{} {
+	return a.args
+}
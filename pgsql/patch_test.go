@@ -0,0 +1,55 @@
+package pgsql_test
+
+import (
+	"testing"
+
+	"pggen/pgsql"
+)
+
+func TestAssignments(t *testing.T) {
+	var set pgsql.Assignments
+	if set.Len() != 0 || set.Clause() != "" {
+		t.Errorf("zero Assignments = %d, %q", set.Len(), set.Clause())
+	}
+
+	set.Add("email", "a@example.com")
+	set.Add("site", 1)
+
+	where := pgsql.Filter{Expr: "id = ?", Args: []interface{}{7}}
+	if stmt := set.Clause() + where.Where(set.Len()+1); stmt != "email = $1, site = $2 where id = $3" {
+		t.Errorf("Assignments with a where clause = %q", stmt)
+	}
+
+	if args := set.Args(); len(args) != 2 || args[0] != "a@example.com" || args[1] != 1 {
+		t.Errorf("Assignments.Args() = %v", args)
+	}
+
+	set.Increment("version")
+	if stmt := set.Clause() + where.Where(set.Len()+1); stmt != "email = $1, site = $2, version = version + 1 where id = $3" {
+		t.Errorf("Assignments with an increment = %q", stmt)
+	}
+
+	if o := pgsql.Set("x"); !o.Set || o.Value != "x" {
+		t.Errorf("Set(x) = %+v", o)
+	}
+
+	var unset pgsql.Optional[string]
+	if unset.Set {
+		t.Errorf("zero Optional is set")
+	}
+}
+
+func TestPatchArg(t *testing.T) {
+	tests := map[string]string{
+		"string":   "patch.Name.Value",
+		"[]byte":   "patch.Name.Value",
+		"[]string": "pgsql.Array(patch.Name.Value)",
+	}
+
+	for goType, expected := range tests {
+		c := &pgsql.Column{Name: "name", GoName: "Name", GoType: goType}
+		if arg := pgsql.PatchArg(c, "patch"); arg != expected {
+			t.Errorf("PatchArg of %s = %q, expected %q", goType, arg, expected)
+		}
+	}
+}
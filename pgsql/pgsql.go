@@ -5,26 +5,68 @@ import (
 	"database/sql"
 	"fmt"
 	"reflect"
-	"strings"
 	"time"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	// github.com/lib/pq is imported to initalize the postgres driver
 	_ "github.com/lib/pq"
 )
 
-// PgSQL is a wrapper around a postgres sql.DB
+// PgSQL is a wrapper around a postgres sql.DB, and the pgxpool.Pool behind it when opened with DriverPgx
 type PgSQL struct {
-	Db *sql.DB
+	Db    *sql.DB
+	Pool  *pgxpool.Pool
+	tx    *txState
+	stmts *stmtCache
 }
 
-// NewPgSQL opens a connection to a postgres database using the connection string
-func NewPgSQL(connectionString string) (*PgSQL, error) {
+// NewPgSQL opens a connection pool to a postgres database using the connection string,
+// configured by the options. Connections are opened when first needed, unless
+// WithStartupCheck is given
+func NewPgSQL(connectionString string, opts ...Option) (*PgSQL, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if o.driver == DriverPgx {
+		return newPgxPgSQL(connectionString, &o)
+	}
+
+	connectionString, err := withRuntimeParams(connectionString, o.runtimeParams)
+	if err != nil {
+		return nil, err
+	}
+
 	Db, err := sql.Open("postgres", connectionString)
+	if err != nil {
+		return &PgSQL{Db: Db}, err
+	}
+
+	pg := NewPgSQLFromDB(Db, opts...)
+	if err := o.check(pg); err != nil {
+		Db.Close()
+		return nil, err
+	}
+
+	return pg, nil
+}
+
+// NewPgSQLFromDB wraps an already opened connection pool, configured by the pool and statement
+// cache options. Connection string options, such as run-time parameters, and the startup check are ignored
+func NewPgSQLFromDB(Db *sql.DB, opts ...Option) *PgSQL {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
 
-	pg := new(PgSQL)
-	pg.Db = Db
+	pg := &PgSQL{Db: Db}
+	if !o.noStmtCache {
+		pg.stmts = newStmtCache()
+	}
+	o.configure(pg)
 
-	return pg, err
+	return pg
 }
 
 // Count returns row count
@@ -39,6 +81,15 @@ func (pg *PgSQL) Count(tableName string) (int64, error) {
 	return n, err
 }
 
+// columnName returns the column a struct field maps to, the db tag if present otherwise the field name
+func columnName(f reflect.StructField) string {
+	if tag := f.Tag.Get("db"); len(tag) > 0 {
+		return tag
+	}
+
+	return f.Name
+}
+
 // WhereClause accepts a struct in the form of name = value
 // where name is a table column name and value is the value
 // desired in the where clause
@@ -48,7 +99,10 @@ func WhereClause(s interface{}) string {
 	var b bytes.Buffer
 	sep := " where "
 	for i := 0; i < e.NumField(); i++ {
-		nam := e.Field(i).Name
+		if e.Field(i).PkgPath != "" {
+			continue
+		}
+		nam := columnName(e.Field(i))
 		typ := e.Field(i).Type
 		val := reflect.ValueOf(s).Field(i)
 
@@ -73,7 +127,10 @@ func SelectClause(s interface{}) string {
 	var b bytes.Buffer
 	sep := "select"
 	for i := 0; i < e.NumField(); i++ {
-		nam := e.Field(i).Name
+		if e.Field(i).PkgPath != "" {
+			continue
+		}
+		nam := columnName(e.Field(i))
 		b.WriteString(fmt.Sprintf("%v %v", sep, nam))
 		sep = ","
 	}
@@ -92,22 +149,37 @@ func getType(s interface{}) string {
 	return t.Name()
 }
 
+// isReadOnly reports whether a struct field maps to an identity or generated column, tagged
+// pggen:"readonly" in generated structs, which postgres assigns and must not be written
+func isReadOnly(f reflect.StructField) bool {
+	return f.Tag.Get("pggen") == "readonly"
+}
+
 // InsertClause returns a insert clause based on the fields in s
 // in the form of "insert into name (field1, field2) values ({0}, {1})"
+// Fields tagged pggen:"readonly" are left out
 func InsertClause(s interface{}, name string) string {
 	e := reflect.TypeOf(s)
+	if e.Kind() == reflect.Ptr {
+		e = e.Elem()
+	}
 	var b bytes.Buffer
 	sep := "insert into"
 	b.WriteString(fmt.Sprintf("%v %v ", sep, name))
 	sep = "("
+	n := 0
 	for i := 0; i < e.NumField(); i++ {
-		name = e.Field(i).Name
+		if e.Field(i).PkgPath != "" || isReadOnly(e.Field(i)) {
+			continue
+		}
+		name = columnName(e.Field(i))
 		b.WriteString(fmt.Sprintf("%v %v", sep, name))
 		sep = ","
+		n++
 	}
 
 	sep = ") values ("
-	for i := 0; i < e.NumField(); i++ {
+	for i := 0; i < n; i++ {
 		b.WriteString(fmt.Sprintf("%v $%d", sep, i+1))
 		sep = ","
 	}
@@ -118,6 +190,31 @@ func InsertClause(s interface{}, name string) string {
 
 }
 
+// FieldValues returns the values of the exported fields of the struct s, in field order,
+// for use as the arguments of the statement built by InsertClause. Slices are wrapped by Array,
+// fields tagged pggen:"readonly" are left out
+func FieldValues(s interface{}) []interface{} {
+	v := reflect.ValueOf(s)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+
+	ifs := make([]interface{}, 0, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).PkgPath != "" || isReadOnly(v.Type().Field(i)) {
+			continue
+		}
+		f := v.Field(i)
+		if f.Kind() == reflect.Slice && f.Type() != bytesType && !f.Type().Implements(valuerType) {
+			ifs = append(ifs, Array(f.Interface()))
+			continue
+		}
+		ifs = append(ifs, f.Interface())
+	}
+
+	return ifs
+}
+
 // FieldArguments returns a string in the form "variable.Field1, variable.Field2 or "&variable.Field1, &variable.Field2" if pointers is true
 func FieldArguments(variable string, s interface{}, pointers bool) string {
 	var b bytes.Buffer
@@ -204,6 +301,48 @@ func NonPrimaryKeyNames(columns []*Column, tableConstraints []*TableConstraints)
 	return nonPrimaryKeyNames
 }
 
+// PrimaryKeyColumns returns the columns that make up the primary key
+func PrimaryKeyColumns(columns []*Column, tableConstraints []*TableConstraints) []*Column {
+	primaryKeys := make([]*Column, 0)
+	for _, column := range columns {
+		if IsPrimaryKey(column, tableConstraints) {
+			primaryKeys = append(primaryKeys, column)
+		}
+	}
+
+	return primaryKeys
+}
+
+// ReadOnly reports whether the column is an identity or generated column, whose value postgres
+// assigns, so that it is left out of insert and update statements
+func (c *Column) ReadOnly() bool {
+	return c.IsIdentity || c.IsGenerated
+}
+
+// WritableColumns returns the columns that are not identity or generated columns
+func WritableColumns(columns []*Column) []*Column {
+	writable := make([]*Column, 0)
+	for _, column := range columns {
+		if !column.ReadOnly() {
+			writable = append(writable, column)
+		}
+	}
+
+	return writable
+}
+
+// NonPrimaryKeyColumns returns the columns that are not part of the primary key
+func NonPrimaryKeyColumns(columns []*Column, tableConstraints []*TableConstraints) []*Column {
+	nonPrimaryKeys := make([]*Column, 0)
+	for _, column := range columns {
+		if !IsPrimaryKey(column, tableConstraints) {
+			nonPrimaryKeys = append(nonPrimaryKeys, column)
+		}
+	}
+
+	return nonPrimaryKeys
+}
+
 //PrimaryKeyFunctionArgs accepts a column, the constraints from its table, the PrimaryKey variable name
 //and a bool indication if a pointer is required and returns a string in the form "varname.key1, varname.key2" or "&varname.key1, &varname.key2"
 func PrimaryKeyFunctionArgs(columns []*Column, tableConstraints []*TableConstraints, varname string, isPointer bool) string {
@@ -217,25 +356,62 @@ func PrimaryKeyFunctionArgs(columns []*Column, tableConstraints []*TableConstrai
 
 	for _, column := range columns {
 		if IsPrimaryKey(column, tableConstraints) {
-			b.WriteString(fmt.Sprintf("%v%s%s.%v", sep, pointer, varname, strings.Title(column.Name)))
+			b.WriteString(fmt.Sprintf("%v%s%s.%v", sep, pointer, varname, column.GoName))
 			sep = ", "
 		}
 	}
 	return string(b.Bytes())
 }
 
-// Column models a postgres table's columns
+// Column models a postgres table's columns, or the attributes of a composite type.
+// UDTKind and ElemKind hold pg_type.typtype of the column type and, for arrays, of the
+// element type ElemSchema.ElemName: b base, c composite, d domain, e enum, r range, m multirange.
+// Dimensions is the number of dimensions declared for an array column, 0 when not recorded.
+// Precision and Scale are the declared precision and scale of numeric columns, 0 when unconstrained.
+// IdentityGeneration is ALWAYS or BY DEFAULT for identity columns, IsGenerated is set for
+// GENERATED ALWAYS AS (...) STORED columns
 type Column struct {
-	Name     string
-	Default  string
-	Nullable bool
-	Type     string
+	Name               string
+	Default            string
+	Nullable           bool
+	Type               string
+	Comment            string
+	UDTSchema          string
+	UDTName            string
+	UDTKind            string
+	ElemSchema         string
+	ElemName           string
+	ElemKind           string
+	DomainSchema       string
+	DomainName         string
+	Dimensions         int
+	Precision          int
+	Scale              int
+	IsIdentity         bool
+	IdentityGeneration string
+	IsGenerated        bool
+	Doc                string
+	Annotations        Annotations
+	GoName             string
+	GoType             string
+	Import             string
 }
 
 // Table models a postgres table
 type Table struct {
-	Schema string
-	Name   string
+	Schema      string
+	Name        string
+	Comment     string
+	Doc         string
+	Annotations Annotations
+}
+
+// HasExtension reports whether the named extension, e.g. postgis, is installed in the database
+func (pg *PgSQL) HasExtension(name string) (bool, error) {
+	var installed bool
+	err := pg.Db.QueryRow("select exists (select 1 from pg_extension where extname = $1)", name).Scan(&installed)
+
+	return installed, err
 }
 
 // TableConstraints models a postgres tables constraints
@@ -248,11 +424,8 @@ type TableConstraints struct {
 
 // GetTables returns an array of Table structs
 func (pg *PgSQL) GetTables() ([]*Table, error) {
-	if err := pg.Db.Ping(); err != nil {
-		return nil, err
-	}
-
-	query := "select table_schema, table_name " +
+	query := "select table_schema, table_name, " +
+		"obj_description((quote_ident(table_schema) || '.' || quote_ident(table_name))::regclass, 'pg_class') " +
 		"from information_schema.tables " +
 		"where table_schema not in ('pg_catalog', 'information_schema')"
 
@@ -266,13 +439,16 @@ func (pg *PgSQL) GetTables() ([]*Table, error) {
 
 	for rows.Next() {
 		t := new(Table)
+		var comment sql.NullString
 
-		err := rows.Scan(&t.Schema, &t.Name)
+		err := rows.Scan(&t.Schema, &t.Name, &comment)
 
 		if err != nil {
 			return nil, err
 		}
 
+		t.Comment = nullableToString(comment)
+
 		tables = append(tables, t)
 	}
 
@@ -297,11 +473,14 @@ func nullableToBool(n sql.NullString) bool {
 
 // GetColumns returns a array of Columns as defined in information_schema columns
 func (pg *PgSQL) GetColumns(table *Table) ([]*Column, error) {
-	if err := pg.Db.Ping(); err != nil {
-		return nil, err
-	}
-
-	query := "select column_name, column_default, is_nullable, data_type from information_schema.columns where table_schema = $1 and table_name = $2"
+	query := "select c.column_name, c.column_default, c.is_nullable, c.data_type, " +
+		"col_description((quote_ident(c.table_schema) || '.' || quote_ident(c.table_name))::regclass, c.ordinal_position), " +
+		"c.udt_schema, c.udt_name, t.typtype, en.nspname, et.typname, et.typtype, c.domain_schema, c.domain_name, c.numeric_precision, c.numeric_scale, " +
+		"c.is_identity, c.identity_generation, c.is_generated, " +
+		"(select attndims from pg_attribute where attrelid = (quote_ident(c.table_schema) || '.' || quote_ident(c.table_name))::regclass and attnum = c.ordinal_position) " +
+		"from information_schema.columns c " + typeJoins("c.udt_schema", "c.udt_name") +
+		"where c.table_schema = $1 and c.table_name = $2 " +
+		"order by c.ordinal_position"
 	rows, err := pg.Db.Query(query, table.Schema, table.Name)
 
 	if err != nil {
@@ -313,13 +492,30 @@ func (pg *PgSQL) GetColumns(table *Table) ([]*Column, error) {
 	for rows.Next() {
 		c := new(Column)
 		tmp := struct {
-			Name     sql.NullString
-			Default  sql.NullString
-			Nullable sql.NullString
-			Type     sql.NullString
+			Name               sql.NullString
+			Default            sql.NullString
+			Nullable           sql.NullString
+			Type               sql.NullString
+			Comment            sql.NullString
+			UDTSchema          sql.NullString
+			UDTName            sql.NullString
+			UDTKind            sql.NullString
+			ElemSchema         sql.NullString
+			ElemName           sql.NullString
+			ElemKind           sql.NullString
+			DomainSchema       sql.NullString
+			DomainName         sql.NullString
+			Precision          sql.NullInt64
+			Scale              sql.NullInt64
+			IsIdentity         sql.NullString
+			IdentityGeneration sql.NullString
+			IsGenerated        sql.NullString
+			Dimensions         sql.NullInt64
 		}{}
 
-		err := rows.Scan(&tmp.Name, &tmp.Default, &tmp.Nullable, &tmp.Type)
+		err := rows.Scan(&tmp.Name, &tmp.Default, &tmp.Nullable, &tmp.Type, &tmp.Comment,
+			&tmp.UDTSchema, &tmp.UDTName, &tmp.UDTKind, &tmp.ElemSchema, &tmp.ElemName, &tmp.ElemKind, &tmp.DomainSchema, &tmp.DomainName,
+			&tmp.Precision, &tmp.Scale, &tmp.IsIdentity, &tmp.IdentityGeneration, &tmp.IsGenerated, &tmp.Dimensions)
 		if err != nil {
 			return nil, err
 		}
@@ -328,6 +524,21 @@ func (pg *PgSQL) GetColumns(table *Table) ([]*Column, error) {
 		c.Default = nullableToString(tmp.Default)
 		c.Nullable = nullableToBool(tmp.Nullable)
 		c.Type = nullableToString(tmp.Type)
+		c.Comment = nullableToString(tmp.Comment)
+		c.UDTSchema = nullableToString(tmp.UDTSchema)
+		c.UDTName = nullableToString(tmp.UDTName)
+		c.UDTKind = nullableToString(tmp.UDTKind)
+		c.ElemSchema = nullableToString(tmp.ElemSchema)
+		c.ElemName = nullableToString(tmp.ElemName)
+		c.ElemKind = nullableToString(tmp.ElemKind)
+		c.DomainSchema = nullableToString(tmp.DomainSchema)
+		c.DomainName = nullableToString(tmp.DomainName)
+		c.Precision = int(tmp.Precision.Int64)
+		c.Scale = int(tmp.Scale.Int64)
+		c.IsIdentity = nullableToBool(tmp.IsIdentity)
+		c.IdentityGeneration = nullableToString(tmp.IdentityGeneration)
+		c.IsGenerated = nullableToString(tmp.IsGenerated) == "ALWAYS"
+		c.Dimensions = int(tmp.Dimensions.Int64)
 
 		columns = append(columns, c)
 	}
@@ -335,12 +546,193 @@ func (pg *PgSQL) GetColumns(table *Table) ([]*Column, error) {
 	return columns, nil
 }
 
-// GetTableConstraints returns values from information_schema.table_constraints for the table passed ass an argument
-func (pg *PgSQL) GetTableConstraints(table *Table) ([]*TableConstraints, error) {
-	if err := pg.Db.Ping(); err != nil {
+// typeJoins returns the joins to pg_type, aliased t, describing the type named by the udt schema and name
+// columns of an information_schema view, and to the element type, aliased et, and its schema, aliased
+// en, when it is an array
+func typeJoins(schemaColumn string, nameColumn string) string {
+	return "left join pg_namespace tn on tn.nspname = " + schemaColumn + " " +
+		"left join pg_type t on t.typnamespace = tn.oid and t.typname = " + nameColumn + " " +
+		"left join pg_type et on et.oid = t.typelem and t.typcategory = 'A' " +
+		"left join pg_namespace en on en.oid = et.typnamespace "
+}
+
+// GetCompositeTypes returns the composite types, with their attributes, defined in the user schemas
+func (pg *PgSQL) GetCompositeTypes() ([]*CompositeType, error) {
+	query := "select a.udt_schema, a.udt_name, " +
+		"obj_description((quote_ident(a.udt_schema) || '.' || quote_ident(a.udt_name))::regtype, 'pg_type'), " +
+		"a.attribute_name, a.data_type, " +
+		"col_description((quote_ident(a.udt_schema) || '.' || quote_ident(a.udt_name))::regclass, a.ordinal_position), " +
+		"a.attribute_udt_schema, a.attribute_udt_name, t.typtype, en.nspname, et.typname, et.typtype, a.numeric_precision, a.numeric_scale, " +
+		"(select attndims from pg_attribute where attrelid = (quote_ident(a.udt_schema) || '.' || quote_ident(a.udt_name))::regclass and attnum = a.ordinal_position) " +
+		"from information_schema.attributes a " + typeJoins("a.attribute_udt_schema", "a.attribute_udt_name") +
+		"where a.udt_schema not in ('pg_catalog', 'information_schema') " +
+		"order by a.udt_schema, a.udt_name, a.ordinal_position"
+
+	rows, err := pg.Db.Query(query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	compositeTypes := []*CompositeType{}
+	var ct *CompositeType
+
+	for rows.Next() {
+		c := new(Column)
+		tmp := struct {
+			Schema      sql.NullString
+			Name        sql.NullString
+			TypeComment sql.NullString
+			Attribute   sql.NullString
+			Type        sql.NullString
+			Comment     sql.NullString
+			UDTSchema   sql.NullString
+			UDTName     sql.NullString
+			UDTKind     sql.NullString
+			ElemSchema  sql.NullString
+			ElemName    sql.NullString
+			ElemKind    sql.NullString
+			Precision   sql.NullInt64
+			Scale       sql.NullInt64
+			Dimensions  sql.NullInt64
+		}{}
+
+		err := rows.Scan(&tmp.Schema, &tmp.Name, &tmp.TypeComment, &tmp.Attribute, &tmp.Type, &tmp.Comment,
+			&tmp.UDTSchema, &tmp.UDTName, &tmp.UDTKind, &tmp.ElemSchema, &tmp.ElemName, &tmp.ElemKind,
+			&tmp.Precision, &tmp.Scale, &tmp.Dimensions)
+		if err != nil {
+			return nil, err
+		}
+
+		schema := nullableToString(tmp.Schema)
+		name := nullableToString(tmp.Name)
+		if ct == nil || ct.Schema != schema || ct.Name != name {
+			ct = &CompositeType{
+				Schema:  schema,
+				Name:    name,
+				Comment: nullableToString(tmp.TypeComment),
+			}
+			compositeTypes = append(compositeTypes, ct)
+		}
+
+		c.Name = nullableToString(tmp.Attribute)
+		c.Nullable = true
+		c.Type = nullableToString(tmp.Type)
+		c.Comment = nullableToString(tmp.Comment)
+		c.UDTSchema = nullableToString(tmp.UDTSchema)
+		c.UDTName = nullableToString(tmp.UDTName)
+		c.UDTKind = nullableToString(tmp.UDTKind)
+		c.ElemSchema = nullableToString(tmp.ElemSchema)
+		c.ElemName = nullableToString(tmp.ElemName)
+		c.ElemKind = nullableToString(tmp.ElemKind)
+		c.Precision = int(tmp.Precision.Int64)
+		c.Scale = int(tmp.Scale.Int64)
+		c.Dimensions = int(tmp.Dimensions.Int64)
+
+		ct.Attributes = append(ct.Attributes, c)
+	}
+
+	return compositeTypes, nil
+}
+
+// GetDomains returns the domains, with their check constraints, defined in the user schemas
+func (pg *PgSQL) GetDomains() ([]*Domain, error) {
+	query := "select d.domain_schema, d.domain_name, " +
+		"obj_description((quote_ident(d.domain_schema) || '.' || quote_ident(d.domain_name))::regtype, 'pg_type'), " +
+		"d.data_type, d.udt_schema, d.udt_name, t.typtype, en.nspname, et.typname, et.typtype, d.numeric_precision, d.numeric_scale, " +
+		"(select dt.typnotnull from pg_type dt where dt.oid = (quote_ident(d.domain_schema) || '.' || quote_ident(d.domain_name))::regtype) " +
+		"from information_schema.domains d " + typeJoins("d.udt_schema", "d.udt_name") +
+		"where d.domain_schema not in ('pg_catalog', 'information_schema') " +
+		"order by d.domain_schema, d.domain_name"
+
+	rows, err := pg.Db.Query(query)
+
+	if err != nil {
 		return nil, err
 	}
 
+	domains := []*Domain{}
+	byName := map[string]*Domain{}
+
+	for rows.Next() {
+		d := new(Domain)
+		c := new(Column)
+		tmp := struct {
+			Schema     sql.NullString
+			Name       sql.NullString
+			Comment    sql.NullString
+			Type       sql.NullString
+			UDTSchema  sql.NullString
+			UDTName    sql.NullString
+			UDTKind    sql.NullString
+			ElemSchema sql.NullString
+			ElemName   sql.NullString
+			ElemKind   sql.NullString
+			Precision  sql.NullInt64
+			Scale      sql.NullInt64
+			NotNull    sql.NullBool
+		}{}
+
+		err := rows.Scan(&tmp.Schema, &tmp.Name, &tmp.Comment, &tmp.Type,
+			&tmp.UDTSchema, &tmp.UDTName, &tmp.UDTKind, &tmp.ElemSchema, &tmp.ElemName, &tmp.ElemKind,
+			&tmp.Precision, &tmp.Scale, &tmp.NotNull)
+		if err != nil {
+			return nil, err
+		}
+
+		d.Schema = nullableToString(tmp.Schema)
+		d.Name = nullableToString(tmp.Name)
+		d.Comment = nullableToString(tmp.Comment)
+		d.NotNull = tmp.NotNull.Valid && tmp.NotNull.Bool
+
+		c.Name = d.Name
+		c.Nullable = !d.NotNull
+		c.Type = nullableToString(tmp.Type)
+		c.UDTSchema = nullableToString(tmp.UDTSchema)
+		c.UDTName = nullableToString(tmp.UDTName)
+		c.UDTKind = nullableToString(tmp.UDTKind)
+		c.ElemSchema = nullableToString(tmp.ElemSchema)
+		c.ElemName = nullableToString(tmp.ElemName)
+		c.ElemKind = nullableToString(tmp.ElemKind)
+		c.Precision = int(tmp.Precision.Int64)
+		c.Scale = int(tmp.Scale.Int64)
+		d.Base = c
+
+		domains = append(domains, d)
+		byName[d.Schema+"."+d.Name] = d
+	}
+
+	query = "select n.nspname, t.typname, c.conname, pg_get_constraintdef(c.oid) " +
+		"from pg_constraint c " +
+		"join pg_type t on t.oid = c.contypid " +
+		"join pg_namespace n on n.oid = t.typnamespace " +
+		"where c.contype = 'c' and n.nspname not in ('pg_catalog', 'information_schema') " +
+		"order by n.nspname, t.typname, c.conname"
+
+	rows, err = pg.Db.Query(query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for rows.Next() {
+		var schema, name string
+		check := new(DomainCheck)
+
+		if err := rows.Scan(&schema, &name, &check.Name, &check.Definition); err != nil {
+			return nil, err
+		}
+
+		if d, ok := byName[schema+"."+name]; ok {
+			d.Checks = append(d.Checks, check)
+		}
+	}
+
+	return domains, nil
+}
+
+// GetTableConstraints returns values from information_schema.table_constraints for the table passed ass an argument
+func (pg *PgSQL) GetTableConstraints(table *Table) ([]*TableConstraints, error) {
 	query := "select tc.constraint_type, tc.initially_deferred, tc.is_deferrable, cu.column_name from information_schema.table_constraints tc " +
 		"join information_schema.constraint_column_usage cu on cu.Table_schema = tc.table_schema and cu.table_name = tc.table_name and cu.constraint_name = tc.constraint_name " +
 		"where tc.table_schema = $1 and tc.table_name = $2"
@@ -0,0 +1,165 @@
+package pgsql_test
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+
+	"pggen/pgsql"
+)
+
+func TestInsertClauseReadOnly(t *testing.T) {
+	s := struct {
+		ID    int    `db:"id" pggen:"readonly"`
+		Name  string `db:"name"`
+		Total int    `db:"total" pggen:"readonly"`
+		Email string `db:"email"`
+	}{ID: 1, Name: "a", Total: 2, Email: "b"}
+
+	if clause := pgsql.InsertClause(s, "member"); clause != "insert into member ( name, email) values ( $1, $2)" {
+		t.Errorf("InsertClause = %q", clause)
+	}
+
+	if clause := pgsql.InsertClause(&s, "member"); clause != "insert into member ( name, email) values ( $1, $2)" {
+		t.Errorf("InsertClause of a pointer = %q", clause)
+	}
+
+	values := pgsql.FieldValues(s)
+	if len(values) != 2 || values[0] != "a" || values[1] != "b" {
+		t.Errorf("FieldValues = %v", values)
+	}
+}
+
+func TestWritableColumns(t *testing.T) {
+	columns := []*pgsql.Column{
+		{Name: "id", IsIdentity: true, IdentityGeneration: "ALWAYS"},
+		{Name: "name"},
+		{Name: "search", IsGenerated: true},
+	}
+
+	writable := pgsql.WritableColumns(columns)
+	if len(writable) != 1 || writable[0].Name != "name" {
+		t.Errorf("WritableColumns = %v", writable)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	err := pgsql.NotFound(sql.ErrNoRows, "public.member")
+
+	var notFound *pgsql.NotFoundError
+	if !errors.As(err, &notFound) || notFound.Table != "public.member" {
+		t.Fatalf("NotFound(sql.ErrNoRows) = %v, expected a *NotFoundError", err)
+	}
+
+	if !errors.Is(err, pgsql.ErrNotFound) || !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("NotFound(sql.ErrNoRows) does not match ErrNotFound and sql.ErrNoRows")
+	}
+
+	other := errors.New("connection refused")
+	if err := pgsql.NotFound(other, "public.member"); err != other {
+		t.Errorf("NotFound of another error = %v, expected it unchanged", err)
+	}
+
+	if err := error(&pgsql.NotFoundError{Table: "public.member"}); !errors.Is(err, pgsql.ErrNotFound) || errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("NotFoundError without an underlying error matched %v", err)
+	}
+}
+
+func TestStale(t *testing.T) {
+	err := pgsql.Stale(sql.ErrNoRows, "public.site")
+
+	var stale *pgsql.StaleObjectError
+	if !errors.As(err, &stale) || stale.Table != "public.site" {
+		t.Fatalf("Stale(sql.ErrNoRows) = %v, expected a *StaleObjectError", err)
+	}
+
+	if !errors.Is(err, pgsql.ErrStaleObject) || !errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgsql.ErrNotFound) {
+		t.Errorf("Stale(sql.ErrNoRows) does not match only ErrStaleObject and sql.ErrNoRows")
+	}
+
+	err = pgsql.Stale(&pgsql.NotFoundError{Table: "public.site", Err: sql.ErrNoRows}, "public.site")
+	if !errors.Is(err, pgsql.ErrStaleObject) || !errors.Is(err, sql.ErrNoRows) || errors.Is(err, pgsql.ErrNotFound) {
+		t.Errorf("Stale of a *NotFoundError = %v, expected it to match only ErrStaleObject and sql.ErrNoRows", err)
+	}
+
+	unique := &pq.Error{Code: "23505", Constraint: "site_pkey"}
+	if err := pgsql.Stale(unique, "public.site"); !errors.Is(err, pgsql.ErrUniqueViolation) {
+		t.Errorf("Stale of a unique violation = %v, expected it classified", err)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	unique := &pq.Error{Code: "23505", Constraint: "member_email_key", Detail: "Key (email, site)=(a@example.com, 1) already exists."}
+
+	err := pgsql.Classify(fmt.Errorf("insert: %w", unique), "public.member")
+
+	var violation *pgsql.UniqueViolation
+	if !errors.As(err, &violation) {
+		t.Fatalf("Classify of a unique violation = %v", err)
+	}
+
+	if violation.Table != "public.member" || violation.Constraint != "member_email_key" || len(violation.Columns) != 2 || violation.Columns[1] != "site" {
+		t.Errorf("Classify of a unique violation = %+v", violation)
+	}
+
+	var pqErr *pq.Error
+	if !errors.Is(err, pgsql.ErrUniqueViolation) || !errors.As(err, &pqErr) {
+		t.Errorf("UniqueViolation does not match ErrUniqueViolation and the driver error")
+	}
+
+	sentinels := map[string]error{
+		"23503": pgsql.ErrForeignKeyViolation,
+		"23514": pgsql.ErrCheckViolation,
+		"40001": pgsql.ErrSerializationFailure,
+		"40P01": pgsql.ErrDeadlock,
+	}
+
+	for code, sentinel := range sentinels {
+		err := pgsql.Classify(&pq.Error{Code: pq.ErrorCode(code), Constraint: "c"}, "public.member")
+		if !errors.Is(err, sentinel) {
+			t.Errorf("Classify of %s = %v, expected %v", code, err, sentinel)
+		}
+
+		for _, other := range sentinels {
+			if other != sentinel && errors.Is(err, other) {
+				t.Errorf("Classify of %s matched %v", code, other)
+			}
+		}
+	}
+
+	var check *pgsql.CheckViolation
+	if err := pgsql.Classify(&pq.Error{Code: "23514", Constraint: "price_positive"}, "xdemo.gadget"); !errors.As(err, &check) || check.Constraint != "price_positive" || check.Table != "xdemo.gadget" {
+		t.Errorf("Classify of a check violation = %v", err)
+	}
+
+	if err := pgsql.Classify(sql.ErrNoRows, "public.member"); !errors.Is(err, pgsql.ErrNotFound) {
+		t.Errorf("Classify of sql.ErrNoRows = %v, expected ErrNotFound", err)
+	}
+
+	if err := pgsql.Classify(pgx.ErrNoRows, "public.member"); !errors.Is(err, pgsql.ErrNotFound) {
+		t.Errorf("Classify of pgx.ErrNoRows = %v, expected ErrNotFound", err)
+	}
+
+	pgErr := &pgconn.PgError{Code: "23505", ConstraintName: "member_email_key", Detail: "Key (email)=(a@example.com) already exists."}
+	if err := pgsql.Classify(pgErr, "public.member"); !errors.As(err, &violation) || violation.Constraint != "member_email_key" || len(violation.Columns) != 1 || violation.Columns[0] != "email" {
+		t.Errorf("Classify of a pgx unique violation = %v", err)
+	}
+
+	if err := pgsql.Classify(&pgconn.PgError{Code: "40001"}, "public.member"); !errors.Is(err, pgsql.ErrSerializationFailure) {
+		t.Errorf("Classify of a pgx serialization failure = %v", err)
+	}
+
+	other := &pq.Error{Code: "42P01"}
+	if err := pgsql.Classify(other, "public.member"); err != other {
+		t.Errorf("Classify of an unclassified error = %v, expected it unchanged", err)
+	}
+
+	if err := pgsql.Classify(nil, "public.member"); err != nil {
+		t.Errorf("Classify(nil) = %v", err)
+	}
+}
@@ -0,0 +1,299 @@
+package pgsql
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Range models a postgres range of T, e.g. [2020-01-01,2020-02-01). LowerInf and UpperInf mark
+// an unbounded side, Empty the empty range, in which case the other fields are ignored.
+// LowerInfinity and UpperInfinity mark a bound of -infinity or infinity, which timestamp and
+// date ranges keep apart from an unbounded side. The zero Range is NULL
+type Range[T any] struct {
+	Lower          T
+	Upper          T
+	LowerInclusive bool
+	UpperInclusive bool
+	LowerInf       bool
+	UpperInf       bool
+	LowerInfinity  bool
+	UpperInfinity  bool
+	Empty          bool
+}
+
+// NewRange returns the range between lower and upper, with bounds "[)", "[]", "(]" or "()" as
+// in the sql range constructors. Postgres applies "[)" when bounds is empty
+func NewRange[T any](lower T, upper T, bounds string) Range[T] {
+	if len(bounds) == 0 {
+		bounds = "[)"
+	}
+
+	return Range[T]{
+		Lower:          lower,
+		Upper:          upper,
+		LowerInclusive: strings.HasPrefix(bounds, "["),
+		UpperInclusive: strings.HasSuffix(bounds, "]"),
+	}
+}
+
+// Scan implements sql.Scanner. NULL scans as the zero Range
+func (r *Range[T]) Scan(src interface{}) error {
+	if src == nil {
+		*r = Range[T]{}
+		return nil
+	}
+
+	text, err := srcText(src)
+	if err != nil {
+		return err
+	}
+
+	p := &literalParser{text: strings.TrimSpace(text)}
+	rt, err := p.rangeLiteral()
+	if err != nil {
+		return err
+	}
+
+	if p.pos != len(p.text) {
+		return fmt.Errorf("pgsql: unexpected %q after range literal", p.text[p.pos:])
+	}
+
+	return r.assign(rt)
+}
+
+// Value implements driver.Valuer, binding the zero Range as NULL
+func (r Range[T]) Value() (driver.Value, error) {
+	if reflect.ValueOf(r).IsZero() {
+		return nil, nil
+	}
+
+	return r.literal()
+}
+
+// assign stores a parsed range literal in r
+func (r *Range[T]) assign(rt rangeText) error {
+	*r = Range[T]{Empty: rt.empty, LowerInclusive: rt.lowerInclusive, UpperInclusive: rt.upperInclusive}
+	if rt.empty {
+		return nil
+	}
+
+	// timestamp and date bounds of -infinity and infinity have no go value, they are flagged instead
+	r.LowerInf, r.UpperInf = rt.lowerInf, rt.upperInf
+	r.LowerInfinity = !r.LowerInf && strings.EqualFold(rt.lower, "-infinity")
+	r.UpperInfinity = !r.UpperInf && strings.EqualFold(rt.upper, "infinity")
+
+	if !r.LowerInf && !r.LowerInfinity {
+		if err := scanText(&r.Lower, rt.lower, false); err != nil {
+			return fmt.Errorf("pgsql: range lower bound: %s", err)
+		}
+	}
+
+	if !r.UpperInf && !r.UpperInfinity {
+		if err := scanText(&r.Upper, rt.upper, false); err != nil {
+			return fmt.Errorf("pgsql: range upper bound: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// literal returns the range literal for r, e.g. [1,10) or empty
+func (r Range[T]) literal() (string, error) {
+	if r.Empty {
+		return "empty", nil
+	}
+
+	var b strings.Builder
+	if r.LowerInclusive && !r.LowerInf {
+		b.WriteByte('[')
+	} else {
+		b.WriteByte('(')
+	}
+
+	switch {
+	case r.LowerInf:
+	case r.LowerInfinity:
+		b.WriteString("-infinity")
+	default:
+		text, null, err := formatText(r.Lower)
+		if err != nil {
+			return "", err
+		}
+		if null {
+			return "", errors.New("pgsql: range lower bound is NULL, set LowerInf for an unbounded range")
+		}
+		b.WriteString(quoteText(text))
+	}
+
+	b.WriteByte(',')
+
+	switch {
+	case r.UpperInf:
+	case r.UpperInfinity:
+		b.WriteString("infinity")
+	default:
+		text, null, err := formatText(r.Upper)
+		if err != nil {
+			return "", err
+		}
+		if null {
+			return "", errors.New("pgsql: range upper bound is NULL, set UpperInf for an unbounded range")
+		}
+		b.WriteString(quoteText(text))
+	}
+
+	if r.UpperInclusive && !r.UpperInf {
+		b.WriteByte(']')
+	} else {
+		b.WriteByte(')')
+	}
+
+	return b.String(), nil
+}
+
+// Multirange models a postgres multirange of T as its ranges in order
+type Multirange[T any] []Range[T]
+
+// Scan implements sql.Scanner. NULL scans as a nil Multirange
+func (m *Multirange[T]) Scan(src interface{}) error {
+	if src == nil {
+		*m = nil
+		return nil
+	}
+
+	text, err := srcText(src)
+	if err != nil {
+		return err
+	}
+
+	p := &literalParser{text: strings.TrimSpace(text)}
+	if p.peek() != '{' {
+		return fmt.Errorf("pgsql: invalid multirange literal %q", text)
+	}
+	p.pos++
+
+	ranges := Multirange[T]{}
+	for {
+		p.skipSpace()
+		if p.peek() == '}' && len(ranges) == 0 {
+			p.pos++
+			break
+		}
+
+		rt, err := p.rangeLiteral()
+		if err != nil {
+			return err
+		}
+
+		var r Range[T]
+		if err := r.assign(rt); err != nil {
+			return err
+		}
+		ranges = append(ranges, r)
+
+		p.skipSpace()
+		if p.peek() == ',' {
+			p.pos++
+			continue
+		}
+		if p.peek() != '}' {
+			return fmt.Errorf("pgsql: unterminated multirange literal %q", text)
+		}
+		p.pos++
+		break
+	}
+
+	if p.pos != len(p.text) {
+		return fmt.Errorf("pgsql: unexpected %q after multirange literal", p.text[p.pos:])
+	}
+
+	*m = ranges
+
+	return nil
+}
+
+// Value implements driver.Valuer, a nil Multirange is NULL
+func (m Multirange[T]) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+
+	literals := make([]string, len(m))
+	for i, r := range m {
+		literal, err := r.literal()
+		if err != nil {
+			return nil, err
+		}
+		literals[i] = literal
+	}
+
+	return "{" + strings.Join(literals, ",") + "}", nil
+}
+
+// rangeText is a parsed range literal with the bounds still in text form
+type rangeText struct {
+	lower          string
+	upper          string
+	lowerInclusive bool
+	upperInclusive bool
+	lowerInf       bool
+	upperInf       bool
+	empty          bool
+}
+
+// rangeLiteral reads a range literal such as [1,10), ("a b",] or empty
+func (p *literalParser) rangeLiteral() (rangeText, error) {
+	var rt rangeText
+
+	if strings.HasPrefix(strings.ToLower(p.text[p.pos:]), "empty") {
+		p.pos += len("empty")
+		rt.empty = true
+		return rt, nil
+	}
+
+	switch p.peek() {
+	case '[':
+		rt.lowerInclusive = true
+	case '(':
+	default:
+		return rt, fmt.Errorf("pgsql: expected '[' or '(' at %d in %q", p.pos, p.text)
+	}
+	p.pos++
+
+	lower, quoted, err := p.value(",")
+	if err != nil {
+		return rt, err
+	}
+	rt.lower, rt.lowerInf = lower, !quoted && len(lower) == 0
+
+	if p.peek() != ',' {
+		return rt, fmt.Errorf("pgsql: expected ',' at %d in %q", p.pos, p.text)
+	}
+	p.pos++
+
+	upper, quoted, err := p.value(")]")
+	if err != nil {
+		return rt, err
+	}
+	rt.upper, rt.upperInf = upper, !quoted && len(upper) == 0
+
+	switch p.peek() {
+	case ']':
+		rt.upperInclusive = true
+	case ')':
+	default:
+		return rt, fmt.Errorf("pgsql: unterminated range literal %q", p.text)
+	}
+	p.pos++
+
+	return rt, nil
+}
+
+func (p *literalParser) skipSpace() {
+	for p.peek() == ' ' {
+		p.pos++
+	}
+}
@@ -0,0 +1,142 @@
+package pgsql_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"pggen/pgsql"
+)
+
+func TestRange(t *testing.T) {
+	literals := map[string]pgsql.Range[int]{
+		"[1,10)": pgsql.NewRange(1, 10, "[)"),
+		"(1,10]": pgsql.NewRange(1, 10, "(]"),
+		"empty":  {Empty: true},
+		"(,5)":   {Upper: 5, LowerInf: true},
+		"[3,)":   {Lower: 3, LowerInclusive: true, UpperInf: true},
+		"(,)":    {LowerInf: true, UpperInf: true},
+	}
+
+	for literal, r := range literals {
+		var scanned pgsql.Range[int]
+		if err := scanned.Scan([]byte(literal)); err != nil {
+			t.Fatalf("Scan(%q) returned error %s", literal, err)
+		}
+
+		if scanned != r {
+			t.Errorf("Scan(%q) = %+v, expected %+v", literal, scanned, r)
+		}
+
+		v, err := r.Value()
+		if err != nil {
+			t.Fatalf("Value of %+v returned error %s", r, err)
+		}
+
+		var again pgsql.Range[int]
+		if err := again.Scan(v); err != nil || again != r {
+			t.Errorf("Value of %+v = %v does not scan back", r, v)
+		}
+	}
+
+	var tr pgsql.Range[time.Time]
+	if err := tr.Scan(`["2020-01-01 00:00:00+00",infinity)`); err != nil {
+		t.Fatalf("Scan of a tstzrange returned error %s", err)
+	}
+
+	if !tr.Lower.Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)) || !tr.LowerInclusive || !tr.UpperInfinity || tr.UpperInf {
+		t.Errorf("Scan of a tstzrange = %+v, expected an upper bound of infinity", tr)
+	}
+
+	if v, err := tr.Value(); err != nil || v != `["2020-01-01 00:00:00+00:00",infinity)` {
+		t.Errorf("Value of a tstzrange with an infinity bound = %v, %v", v, err)
+	}
+
+	if err := tr.Scan(`(-infinity,)`); err != nil || !tr.LowerInfinity || tr.LowerInf || !tr.UpperInf || tr.UpperInfinity {
+		t.Errorf("Scan of (-infinity,) = %+v, %v, expected a lower bound of -infinity and no upper bound", tr, err)
+	}
+
+	if v, err := tr.Value(); err != nil || v != `(-infinity,)` {
+		t.Errorf("Value of (-infinity,) = %v, %v", v, err)
+	}
+
+	var null pgsql.Range[int]
+	if err := null.Scan(nil); err != nil || null != (pgsql.Range[int]{}) {
+		t.Errorf("Scan(nil) = %+v, %v, expected the zero Range", null, err)
+	}
+
+	if v, err := null.Value(); err != nil || v != nil {
+		t.Errorf("Value of the zero Range = %v, %v, expected NULL", v, err)
+	}
+
+	if v, err := (pgsql.Range[int]{Empty: true}).Value(); err != nil || v != "empty" {
+		t.Errorf("Value of the empty Range = %v, %v, expected empty", v, err)
+	}
+}
+
+func TestMultirange(t *testing.T) {
+	var m pgsql.Multirange[int64]
+	if err := m.Scan([]byte("{[1,3), [5,7)}")); err != nil {
+		t.Fatalf("Scan returned error %s", err)
+	}
+
+	expected := pgsql.Multirange[int64]{pgsql.NewRange[int64](1, 3, "[)"), pgsql.NewRange[int64](5, 7, "[)")}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("Scan = %+v, expected %+v", m, expected)
+	}
+
+	if v, err := m.Value(); err != nil || v != `{["1","3"),["5","7")}` {
+		t.Errorf("Value = %v", v)
+	}
+
+	if err := m.Scan("{}"); err != nil || m == nil || len(m) != 0 {
+		t.Errorf("Scan of {} = %#v, %v", m, err)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	f := pgsql.And(
+		pgsql.Filter{Expr: "during && ?::tstzrange", Args: []interface{}{1}},
+		pgsql.Filter{},
+		pgsql.Or(pgsql.Filter{Expr: "seats @> ?::int4", Args: []interface{}{2}}, pgsql.Filter{Expr: "seats is null"}),
+	)
+
+	if where := f.Where(3); where != " where (during && $3::tstzrange) and ((seats @> $4::int4) or (seats is null))" {
+		t.Errorf("Where = %s", where)
+	}
+
+	if len(f.Args) != 2 {
+		t.Errorf("Args = %v", f.Args)
+	}
+
+	if where := (pgsql.Filter{}).Where(1); where != "" {
+		t.Errorf("Where of the zero Filter = %q", where)
+	}
+
+	quoted := pgsql.Filter{Expr: `tags ?? ? and note <> 'why?' and "odd?" = ? and tags ??| ?`, Args: []interface{}{"x", 1, "y"}}
+	if where := quoted.Where(1); where != ` where tags ? $1 and note <> 'why?' and "odd?" = $2 and tags ?| $3` {
+		t.Errorf("Where with quoted and escaped question marks = %s", where)
+	}
+
+	if where := (pgsql.Filter{Expr: `note = 'it''s?' and id = ?`}).Where(1); where != ` where note = 'it''s?' and id = $1` {
+		t.Errorf("Where with a doubled quote = %s", where)
+	}
+
+	opts := pgsql.ListOptions{OrderBy: "id desc", Limit: 10, Offset: 20}
+	if clause := opts.Clause(3); clause != " order by id desc limit $3 offset $4" {
+		t.Errorf("Clause = %s", clause)
+	}
+
+	before := make([]interface{}, 2, 3)
+	if args := opts.Args(before); len(args) != 4 || args[2] != int64(10) || args[3] != int64(20) {
+		t.Errorf("Args = %v", args)
+	}
+
+	if args := (pgsql.ListOptions{Offset: 5}).Args(nil); len(args) != 2 || args[0] != nil || args[1] != int64(5) {
+		t.Errorf("Args of an offset without a limit = %v, expected a NULL limit", args)
+	}
+
+	if clause, args := (pgsql.ListOptions{OrderBy: "id"}).Clause(1), (pgsql.ListOptions{}).Args(nil); clause != " order by id" || len(args) != 0 {
+		t.Errorf("Clause without a limit = %q, %v", clause, args)
+	}
+}
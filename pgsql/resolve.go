@@ -0,0 +1,179 @@
+package pgsql
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Resolver maps columns onto go names and types for the package generated for Schema
+type Resolver struct {
+	Schema      string
+	PackageRoot string
+	// Domains holds the resolved domains keyed by schema.name
+	Domains map[string]*Domain
+	// Overrides maps domain names, plain or schema qualified, to go types given in the
+	// form of a @pggen:type annotation e.g. email=github.com/x/mail.Address
+	Overrides map[string]string
+}
+
+// ResolveTable applies the annotations in the table comment, setting Doc and Annotations
+func ResolveTable(t *Table) []string {
+	var warnings []string
+	t.Doc, t.Annotations, warnings = ParseAnnotations(t.Comment)
+
+	return warnings
+}
+
+// ResolveCompositeType applies the annotations in the comments of a composite type and resolves
+// its attributes. The Resolver should be the one for the schema of the composite type
+func (r *Resolver) ResolveCompositeType(ct *CompositeType) ([]string, error) {
+	var warnings []string
+	ct.Doc, ct.Annotations, warnings = ParseAnnotations(ct.Comment)
+
+	for _, a := range ct.Attributes {
+		w, err := r.ResolveColumn(a)
+		warnings = append(warnings, w...)
+		if err != nil {
+			return warnings, err
+		}
+	}
+
+	return warnings, nil
+}
+
+// ResolveDomain applies the annotations in the comment of a domain, resolves its base type and
+// translates its checks into go. The Resolver should be the one for the schema of the domain.
+// Checks that cannot be translated are reported as warnings and left to the database
+func (r *Resolver) ResolveDomain(d *Domain) ([]string, error) {
+	var warnings []string
+	d.Doc, d.Annotations, warnings = ParseAnnotations(d.Comment)
+
+	w, err := r.ResolveColumn(d.Base)
+	warnings = append(warnings, w...)
+	if err != nil {
+		return warnings, err
+	}
+
+	d.GoName = ""
+	if d.Annotations.Skip || len(r.override(d)) > 0 || !namedBaseTypes[d.Base.GoType] {
+		return warnings, nil
+	}
+
+	d.Patterns = nil
+	d.GoName = strings.Title(d.Name)
+	if len(d.Annotations.Name) > 0 {
+		d.GoName = d.Annotations.Name
+	}
+
+	for _, check := range d.Checks {
+		check.GoExpr, err = d.CheckExpr(check.Definition, "v")
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("check %s not validated: %s", check.Name, err))
+		}
+	}
+
+	return warnings, nil
+}
+
+// override returns the go type configured for a domain, by -t option or @pggen:type annotation
+func (r *Resolver) override(d *Domain) string {
+	if t, ok := r.Overrides[d.Schema+"."+d.Name]; ok {
+		return t
+	}
+
+	if t, ok := r.Overrides[d.Name]; ok {
+		return t
+	}
+
+	return d.Annotations.Type
+}
+
+// ResolveColumn applies the annotations in the column comment, setting Doc, Annotations,
+// GoName, GoType and Import. Unknown markers are returned as warnings, an error is returned
+// if no go type can be found for the column
+func (r *Resolver) ResolveColumn(c *Column) ([]string, error) {
+	var warnings []string
+	c.Doc, c.Annotations, warnings = ParseAnnotations(c.Comment)
+
+	c.GoName = strings.Title(c.Name)
+	if len(c.Annotations.Name) > 0 {
+		c.GoName = c.Annotations.Name
+	}
+
+	c.Import = ""
+	domain := r.Domains[c.DomainSchema+"."+c.DomainName]
+
+	switch {
+	case len(c.Annotations.Type) > 0:
+		c.GoType, c.Import = QualifiedType(c.Annotations.Type)
+	case c.Annotations.Skip:
+	case len(c.Annotations.JSON) > 0:
+		t, imp := QualifiedType(c.Annotations.JSON)
+		c.GoType, c.Import = "pgsql.JSON["+t+"]", imp
+	case domain != nil && len(r.override(domain)) > 0:
+		c.GoType, c.Import = QualifiedType(r.override(domain))
+	case domain != nil && len(domain.GoName) > 0:
+		c.GoType, c.Import = r.userType(domain.Schema, domain.GoName)
+	case c.Type == "USER-DEFINED" && c.UDTKind == "c":
+		c.GoType, c.Import = r.userType(c.UDTSchema, strings.Title(c.UDTName))
+	case c.Type == "ARRAY":
+		t, imp, err := r.elemType(c)
+		if err != nil {
+			return warnings, err
+		}
+
+		dims := c.Dimensions
+		if dims < 1 {
+			dims = 1
+		}
+
+		c.GoType, c.Import = strings.Repeat("[]", dims)+t, imp
+	default:
+		t, imp, err := lookupType(typeName(c))
+		if err != nil {
+			return warnings, err
+		}
+		if t == "pgsql.Numeric" && c.Nullable {
+			t = "pgsql.NullNumeric"
+		}
+		c.GoType, c.Import = t, imp
+	}
+
+	return warnings, nil
+}
+
+// elemType returns the go type, and any import it needs, of the elements of an array column
+func (r *Resolver) elemType(c *Column) (string, string, error) {
+	domain := r.Domains[c.ElemSchema+"."+c.ElemName]
+
+	switch {
+	case domain != nil && len(r.override(domain)) > 0:
+		t, imp := QualifiedType(r.override(domain))
+		return t, imp, nil
+	case domain != nil && len(domain.GoName) > 0:
+		t, imp := r.userType(domain.Schema, domain.GoName)
+		return t, imp, nil
+	case domain != nil:
+		return domain.Base.GoType, domain.Base.Import, nil
+	case c.ElemKind == "c":
+		t, imp := r.userType(c.ElemSchema, strings.Title(c.ElemName))
+		return t, imp, nil
+	}
+
+	t, imp, err := lookupType(c.ElemName)
+	if err != nil {
+		return "", "", fmt.Errorf("%s for array element %s", err, c.ElemName)
+	}
+
+	return t, imp, nil
+}
+
+// userType returns the go type goName generated in the package for typeSchema as seen from
+// the package for the Resolver's schema, and the import needed when they differ
+func (r *Resolver) userType(typeSchema string, goName string) (string, string) {
+	if typeSchema == r.Schema {
+		return goName, ""
+	}
+
+	return typeSchema + "." + goName, r.PackageRoot + "/" + typeSchema
+}
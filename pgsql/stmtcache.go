@@ -0,0 +1,236 @@
+package pgsql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// stmtCache holds the statements prepared for Prepared, keyed by query. A *sql.Stmt prepared on
+// the pool prepares itself on each connection the first time it runs there and reuses that
+type stmtCache struct {
+	mu    sync.Mutex
+	stmts map[string]*sql.Stmt
+}
+
+func newStmtCache() *stmtCache {
+	return &stmtCache{stmts: map[string]*sql.Stmt{}}
+}
+
+// errNotCached is returned by preparedConn.stmt in a transaction for a statement not yet cached
+var errNotCached = errors.New("pgsql: statement not cached")
+
+// cached returns the statement for query when it is cached
+func (c *stmtCache) cached(query string) (*sql.Stmt, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stmt, ok := c.stmts[query]
+
+	return stmt, ok
+}
+
+// get returns the statement for query, preparing it when it is not cached
+func (c *stmtCache) get(ctx context.Context, db *sql.DB, query string) (*sql.Stmt, error) {
+	if stmt, ok := c.cached(query); ok {
+		return stmt, nil
+	}
+
+	stmt, err := db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.stmts[query]; ok {
+		stmt.Close()
+		return cached, nil
+	}
+	c.stmts[query] = stmt
+
+	return stmt, nil
+}
+
+// invalidate closes and forgets the statement for query, when it is still stmt
+func (c *stmtCache) invalidate(query string, stmt *sql.Stmt) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stmts[query] == stmt {
+		delete(c.stmts, query)
+		stmt.Close()
+	}
+}
+
+// close closes every cached statement
+func (c *stmtCache) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for query, stmt := range c.stmts {
+		stmt.Close()
+		delete(c.stmts, query)
+	}
+}
+
+// planChanged reports whether err is postgres refusing to run a prepared statement whose
+// result columns changed since it was prepared, e.g. after an alter table
+func planChanged(err error) bool {
+	code, _, _, message, ok := serverError(err)
+
+	return ok && code == "0A000" && strings.Contains(message, "cached plan must not change result type")
+}
+
+// Prepared returns what generated stores run their constant statements through. With the statement
+// cache enabled, the default for NewPgSQL, each statement is prepared once on each connection and
+// reused, and re-prepared when postgres reports the cached plan changed result type. Outside a
+// transaction the statement is then run again, in a RunInTx closure the transaction fails and
+// RunInTx retries it. Without the cache Prepared returns Conn
+func (pg *PgSQL) Prepared() DBTX {
+	if pg.stmts == nil {
+		return pg.Conn()
+	}
+
+	return preparedConn{pg}
+}
+
+// ClearStatementCache closes the cached prepared statements, e.g. after migrating the schema
+func (pg *PgSQL) ClearStatementCache() {
+	if pg.stmts != nil {
+		pg.stmts.close()
+	}
+}
+
+// preparedConn runs statements through the statement cache of pg
+type preparedConn struct {
+	pg *PgSQL
+}
+
+// stmt returns the cached statement for query, bound to the transaction of pg if any. In a
+// transaction only an already cached statement is used, preparing on the pool would wait for
+// another connection
+func (p preparedConn) stmt(ctx context.Context, query string) (*sql.Stmt, *sql.Stmt, error) {
+	tx := p.pg.Tx()
+	if tx != nil {
+		stmt, ok := p.pg.stmts.cached(query)
+		if !ok {
+			return nil, nil, errNotCached
+		}
+
+		return stmt, tx.StmtContext(ctx, stmt), nil
+	}
+
+	stmt, err := p.pg.stmts.get(ctx, p.pg.Db, query)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return stmt, stmt, nil
+}
+
+// unprepared reports whether a statement that could not be prepared with err runs unprepared
+// instead: when it is not cached in a transaction, or when the plan changed as it was prepared.
+// Any other error, such as a syntax error or a lost connection, is returned as is
+func unprepared(err error) bool {
+	return errors.Is(err, errNotCached) || planChanged(err)
+}
+
+// retry invalidates the statement for query when err reports its plan changed, and reports
+// whether the statement should be run again, only outside a transaction
+func (p preparedConn) retry(query string, stmt *sql.Stmt, err error) bool {
+	if !planChanged(err) {
+		return false
+	}
+
+	p.pg.stmts.invalidate(query, stmt)
+
+	return p.pg.Tx() == nil
+}
+
+// ExecContext implements DBTX
+func (p preparedConn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	for attempt := 0; ; attempt++ {
+		cached, stmt, err := p.stmt(ctx, query)
+		if unprepared(err) {
+			return p.pg.Conn().ExecContext(ctx, query, args...)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		result, err := stmt.ExecContext(ctx, args...)
+		if attempt == 0 && p.retry(query, cached, err) {
+			continue
+		}
+
+		return result, err
+	}
+}
+
+// QueryContext implements DBTX
+func (p preparedConn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	for attempt := 0; ; attempt++ {
+		cached, stmt, err := p.stmt(ctx, query)
+		if unprepared(err) {
+			return p.pg.Conn().QueryContext(ctx, query, args...)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		rows, err := stmt.QueryContext(ctx, args...)
+		if attempt == 0 && p.retry(query, cached, err) {
+			continue
+		}
+
+		return rows, err
+	}
+}
+
+// QueryRowContext implements DBTX
+func (p preparedConn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	for attempt := 0; ; attempt++ {
+		cached, stmt, err := p.stmt(ctx, query)
+		if unprepared(err) {
+			return p.pg.Conn().QueryRowContext(ctx, query, args...)
+		}
+		if err != nil {
+			return errorRow(err)
+		}
+
+		row := stmt.QueryRowContext(ctx, args...)
+		if attempt == 0 && p.retry(query, cached, row.Err()) {
+			continue
+		}
+
+		return row
+	}
+}
+
+// errorRow returns a *sql.Row whose Scan returns err. database/sql has no constructor for one, so
+// the row comes from a database whose connector fails with err
+func errorRow(err error) *sql.Row {
+	db := sql.OpenDB(errorConnector{err})
+	defer db.Close()
+
+	return db.QueryRowContext(context.Background(), "")
+}
+
+// errorConnector is a driver.Connector failing every connection with err
+type errorConnector struct {
+	err error
+}
+
+// Connect implements driver.Connector
+func (c errorConnector) Connect(context.Context) (driver.Conn, error) {
+	return nil, c.err
+}
+
+// Driver implements driver.Connector
+func (c errorConnector) Driver() driver.Driver {
+	return nil
+}
@@ -0,0 +1,206 @@
+package pgsql_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lib/pq"
+
+	"pggen/pgsql"
+)
+
+// stmtDriver is a database/sql driver whose statements return the row (1), counting prepares and
+// logging the statements run. While planChanges is positive its statements fail like postgres
+// after an alter table. Prepares fail with prepareErr when set. Cursor fetches return a row for
+// the first fetches only
+type stmtDriver struct {
+	mu          sync.Mutex
+	prepares    int
+	prepareErr  error
+	planChanges int
+	fetches     int
+	statements  []string
+}
+
+type stmtConn struct{ d *stmtDriver }
+
+type stmtStmt struct {
+	d     *stmtDriver
+	query string
+}
+
+type stmtRows struct{ done bool }
+
+type stmtConnector struct{ d *stmtDriver }
+
+func (c stmtConnector) Connect(context.Context) (driver.Conn, error) { return &stmtConn{c.d}, nil }
+func (c stmtConnector) Driver() driver.Driver                        { return c.d }
+
+func (d *stmtDriver) Open(name string) (driver.Conn, error) { return &stmtConn{d}, nil }
+
+func (c *stmtConn) Prepare(query string) (driver.Stmt, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.prepares++
+	if c.d.prepareErr != nil {
+		return nil, c.d.prepareErr
+	}
+
+	return &stmtStmt{c.d, query}, nil
+}
+func (c *stmtConn) Close() error              { return nil }
+func (c *stmtConn) Begin() (driver.Tx, error) { return c, nil }
+func (c *stmtConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return c, nil
+}
+func (c *stmtConn) Commit() error   { return nil }
+func (c *stmtConn) Rollback() error { return nil }
+
+func (s *stmtStmt) Close() error  { return nil }
+func (s *stmtStmt) NumInput() int { return -1 }
+
+func (s *stmtStmt) failure() error {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	s.d.statements = append(s.d.statements, s.query)
+
+	if s.d.planChanges > 0 {
+		s.d.planChanges--
+		return &pq.Error{Code: "0A000", Message: "cached plan must not change result type"}
+	}
+
+	return nil
+}
+
+func (s *stmtStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if err := s.failure(); err != nil {
+		return nil, err
+	}
+
+	return driver.RowsAffected(1), nil
+}
+
+func (s *stmtStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if err := s.failure(); err != nil {
+		return nil, err
+	}
+
+	if strings.HasPrefix(s.query, "fetch") {
+		s.d.mu.Lock()
+		defer s.d.mu.Unlock()
+		s.d.fetches--
+		return &stmtRows{done: s.d.fetches < 0}, nil
+	}
+
+	return &stmtRows{}, nil
+}
+
+func (r *stmtRows) Columns() []string { return []string{"n"} }
+func (r *stmtRows) Close() error      { return nil }
+func (r *stmtRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(1)
+
+	return nil
+}
+
+func newStmtPgSQL(t *testing.T, opts ...pgsql.Option) (*pgsql.PgSQL, *stmtDriver) {
+	d := &stmtDriver{}
+	db := sql.OpenDB(stmtConnector{d})
+	db.SetMaxOpenConns(1)
+	pg := pgsql.NewPgSQLFromDB(db, opts...)
+	t.Cleanup(func() { db.Close() })
+
+	return pg, d
+}
+
+func queryOne(t *testing.T, conn pgsql.DBTX) {
+	var n int
+	if err := conn.QueryRowContext(context.Background(), "select 1", 7).Scan(&n); err != nil || n != 1 {
+		t.Fatalf("QueryRowContext = %d, %v, expected 1", n, err)
+	}
+}
+
+func TestPrepared(t *testing.T) {
+	pg, d := newStmtPgSQL(t)
+
+	for i := 0; i < 3; i++ {
+		queryOne(t, pg.Prepared())
+	}
+	if _, err := pg.Prepared().ExecContext(context.Background(), "select 1", 7); err != nil {
+		t.Fatalf("ExecContext = %v", err)
+	}
+
+	if d.prepares != 1 {
+		t.Errorf("Prepared prepared the statement %d times, expected 1", d.prepares)
+	}
+
+	d.planChanges = 1
+	queryOne(t, pg.Prepared())
+
+	if d.prepares != 2 {
+		t.Errorf("Prepared prepared the statement %d times after the plan changed, expected 2", d.prepares)
+	}
+
+	pg.ClearStatementCache()
+	queryOne(t, pg.Prepared())
+
+	if d.prepares != 3 {
+		t.Errorf("Prepared prepared the statement %d times after ClearStatementCache, expected 3", d.prepares)
+	}
+}
+
+func TestPreparedError(t *testing.T) {
+	pg, d := newStmtPgSQL(t)
+	d.prepareErr = &pq.Error{Code: "42P01", Message: `relation "missing" does not exist`}
+
+	var n int
+	if err := pg.Prepared().QueryRowContext(context.Background(), "select 2").Scan(&n); err != d.prepareErr {
+		t.Errorf("QueryRowContext of a statement failing to prepare = %v, expected the prepare error", err)
+	}
+	if _, err := pg.Prepared().ExecContext(context.Background(), "select 2"); err != d.prepareErr {
+		t.Errorf("ExecContext of a statement failing to prepare = %v, expected the prepare error", err)
+	}
+	if _, err := pg.Prepared().QueryContext(context.Background(), "select 2"); err != d.prepareErr {
+		t.Errorf("QueryContext of a statement failing to prepare = %v, expected the prepare error", err)
+	}
+
+	if d.prepares != 3 {
+		t.Errorf("Prepared prepared %d times for 3 statements failing to prepare, expected 3 without running them unprepared", d.prepares)
+	}
+}
+
+func TestPreparedInTx(t *testing.T) {
+	pg, d := newStmtPgSQL(t)
+	queryOne(t, pg.Prepared())
+
+	d.planChanges = 1
+	retries, err := pg.RunInTx(context.Background(), nil, func(ctx context.Context, tx *pgsql.PgSQL) error {
+		var n int
+		return tx.Prepared().QueryRowContext(ctx, "select 1", 7).Scan(&n)
+	})
+
+	if err != nil || retries != 1 {
+		t.Errorf("RunInTx = %d, %v, expected 1 retry after the plan changed", retries, err)
+	}
+}
+
+func TestPreparedDisabled(t *testing.T) {
+	pg, d := newStmtPgSQL(t, pgsql.WithStatementCache(false))
+
+	for i := 0; i < 3; i++ {
+		queryOne(t, pg.Prepared())
+	}
+
+	if d.prepares != 3 {
+		t.Errorf("Prepared without the statement cache prepared %d times, expected 3", d.prepares)
+	}
+}
@@ -0,0 +1,154 @@
+package pgsql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// TSVector models a postgres tsvector, its lexemes in the sorted order postgres outputs them.
+// A nil TSVector is NULL
+type TSVector []Lexeme
+
+// Lexeme is a normalized word of a tsvector and the positions it occurs at, if recorded
+type Lexeme struct {
+	Word      string
+	Positions []LexemePosition
+}
+
+// LexemePosition is a position of a lexeme with its weight, A to D. A zero Weight is D
+type LexemePosition struct {
+	Position int
+	Weight   byte
+}
+
+// TSQuery holds a postgres tsquery in its text form, e.g. 'fat' & 'rat'
+type TSQuery string
+
+// Scan implements sql.Scanner
+func (v *TSVector) Scan(src interface{}) error {
+	if src == nil {
+		*v = nil
+		return nil
+	}
+
+	text, err := srcText(src)
+	if err != nil {
+		return err
+	}
+
+	vector := TSVector{}
+	for i := 0; i < len(text); {
+		if text[i] == ' ' {
+			i++
+			continue
+		}
+
+		if text[i] != '\'' {
+			return fmt.Errorf("pgsql: invalid tsvector %q", text)
+		}
+
+		var word strings.Builder
+		for i++; ; i++ {
+			if i >= len(text) {
+				return fmt.Errorf("pgsql: unterminated lexeme in tsvector %q", text)
+			}
+			if text[i] == '\\' && i+1 < len(text) {
+				i++
+			} else if text[i] == '\'' {
+				if i+1 < len(text) && text[i+1] == '\'' {
+					i++
+				} else {
+					i++
+					break
+				}
+			}
+			word.WriteByte(text[i])
+		}
+
+		lexeme := Lexeme{Word: word.String()}
+		if i < len(text) && text[i] == ':' {
+			end := strings.IndexByte(text[i:], ' ')
+			if end < 0 {
+				end = len(text) - i
+			}
+
+			for _, p := range strings.Split(text[i+1:i+end], ",") {
+				position := LexemePosition{Weight: 'D'}
+				if n := len(p); n > 0 && p[n-1] >= 'A' && p[n-1] <= 'D' {
+					position.Weight, p = p[n-1], p[:n-1]
+				}
+
+				if position.Position, err = strconv.Atoi(p); err != nil {
+					return fmt.Errorf("pgsql: invalid lexeme position in tsvector %q", text)
+				}
+				lexeme.Positions = append(lexeme.Positions, position)
+			}
+			i += end
+		}
+
+		vector = append(vector, lexeme)
+	}
+
+	*v = vector
+
+	return nil
+}
+
+// Value implements driver.Valuer
+func (v TSVector) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	var b strings.Builder
+	for i, lexeme := range v {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+
+		b.WriteString("'" + strings.NewReplacer(`\`, `\\`, "'", "''").Replace(lexeme.Word) + "'")
+
+		for j, p := range lexeme.Positions {
+			if j == 0 {
+				b.WriteByte(':')
+			} else {
+				b.WriteByte(',')
+			}
+
+			b.WriteString(strconv.Itoa(p.Position))
+			if p.Weight != 0 && p.Weight != 'D' {
+				b.WriteByte(p.Weight)
+			}
+		}
+	}
+
+	return b.String(), nil
+}
+
+// Scan implements sql.Scanner
+func (q *TSQuery) Scan(src interface{}) error {
+	if src == nil {
+		*q = ""
+		return nil
+	}
+
+	text, err := srcText(src)
+	if err != nil {
+		return err
+	}
+
+	*q = TSQuery(text)
+
+	return nil
+}
+
+// Value implements driver.Valuer, the empty TSQuery is NULL
+func (q TSQuery) Value() (driver.Value, error) {
+	if len(q) == 0 {
+		return nil, nil
+	}
+
+	return string(q), nil
+}
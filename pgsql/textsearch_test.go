@@ -0,0 +1,79 @@
+package pgsql_test
+
+import (
+	"testing"
+
+	"pggen/pgsql"
+)
+
+func TestTSVector(t *testing.T) {
+	// SELECT setweight(to_tsvector('english', 'fat cats ate fat rats'), 'A') || 'it''s a\\b'::tsvector
+	text := `'a\\b' 'ate':3A 'cat':2A 'fat':1A,4A 'it''s' 'rat':5A`
+
+	var v pgsql.TSVector
+	if err := v.Scan([]byte(text)); err != nil {
+		t.Fatalf("Scan returned error %s", err)
+	}
+
+	expected := pgsql.TSVector{
+		{Word: `a\b`},
+		{Word: "ate", Positions: []pgsql.LexemePosition{{Position: 3, Weight: 'A'}}},
+		{Word: "cat", Positions: []pgsql.LexemePosition{{Position: 2, Weight: 'A'}}},
+		{Word: "fat", Positions: []pgsql.LexemePosition{{Position: 1, Weight: 'A'}, {Position: 4, Weight: 'A'}}},
+		{Word: "it's"},
+		{Word: "rat", Positions: []pgsql.LexemePosition{{Position: 5, Weight: 'A'}}},
+	}
+
+	if !pgsql.Equal(v, expected) {
+		t.Errorf("Scan = %v, expected %v", v, expected)
+	}
+
+	if value, err := v.Value(); err != nil || value != text {
+		t.Errorf("Value = %v, expected %s", value, text)
+	}
+
+	if err := v.Scan("'fat':1 'rat':2"); err != nil || v[1].Positions[0] != (pgsql.LexemePosition{Position: 2, Weight: 'D'}) {
+		t.Errorf("Scan of an unweighted position = %v, %v", v, err)
+	}
+
+	if value, err := v.Value(); err != nil || value != "'fat':1 'rat':2" {
+		t.Errorf("Value of unweighted positions = %v", value)
+	}
+
+	if err := v.Scan("'fat"); err == nil {
+		t.Errorf("Scan of an unterminated lexeme did not return an error")
+	}
+
+	if value, err := pgsql.TSVector(nil).Value(); err != nil || value != nil {
+		t.Errorf("Value of a nil TSVector = %v, expected NULL", value)
+	}
+}
+
+func TestSearchColumns(t *testing.T) {
+	columns := []*pgsql.Column{
+		{Name: "notes", GoName: "Notes", GoType: "string"},
+		{Name: "body", GoName: "Body", GoType: "pgsql.TSVector", Annotations: pgsql.Annotations{Headline: "notes", TSConfig: "english"}},
+		{Name: "words", GoName: "Words", GoType: "pgsql.TSVector", Annotations: pgsql.Annotations{Headline: "missing"}},
+	}
+
+	search, warnings := pgsql.SearchColumns(columns)
+	if len(search) != 2 || len(warnings) != 1 {
+		t.Fatalf("SearchColumns = %v, %v", search, warnings)
+	}
+
+	if search[0].Method != "SearchBody" || search[0].Headline != "notes" || search[0].ConfigArg != "'english', " {
+		t.Errorf("SearchColumns body = %+v", search[0])
+	}
+
+	if search[1].Method != "SearchWords" || search[1].Headline != "" || search[1].ConfigArg != "" {
+		t.Errorf("SearchColumns words = %+v", search[1])
+	}
+
+	if search, _ = pgsql.SearchColumns(columns[:2]); search[0].Method != "Search" {
+		t.Errorf("SearchColumns of a single tsvector column named the method %s", search[0].Method)
+	}
+
+	if _, a, _ := pgsql.ParseAnnotations("@pggen:tsconfig=english');drop"); a.TSConfig != "" {
+		t.Errorf("ParseAnnotations accepted the text search configuration %q", a.TSConfig)
+	}
+}
@@ -0,0 +1,218 @@
+package pgsql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Defaults for the zero fields of TxOptions
+const (
+	DefaultTxRetries   = 5
+	DefaultTxBaseDelay = 10 * time.Millisecond
+	DefaultTxMaxDelay  = time.Second
+)
+
+// DBTX is implemented by *sql.DB and *sql.Tx, generated stores run their statements through it
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// TxOptions configures RunInTx. A nil *TxOptions runs at serializable isolation with the default retries
+type TxOptions struct {
+	// Isolation is the transaction isolation level, sql.LevelDefault for the server default
+	Isolation sql.IsolationLevel
+	ReadOnly  bool
+	// Retries is the most times the closure is retried, 0 for DefaultTxRetries, negative for none
+	Retries int
+	// BaseDelay and MaxDelay bound the jittered exponential backoff between attempts,
+	// 0 for DefaultTxBaseDelay and DefaultTxMaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// OnRetry, when set, is called with the failed attempt number, from 1, and its error before each retry
+	OnRetry func(attempt int, err error)
+}
+
+// txState is the state of the transaction of a PgSQL passed to a RunInTx closure, tx on
+// database/sql or pgxTx on pgx
+type txState struct {
+	tx            *sql.Tx
+	pgxTx         pgx.Tx
+	attempt       int
+	nonIdempotent bool
+}
+
+// Conn returns what generated stores run statements through, the transaction of a PgSQL passed
+// to a RunInTx closure, otherwise Db. In a transaction on pgx, statements must go through Pgx
+func (pg *PgSQL) Conn() DBTX {
+	if pg.tx != nil && pg.tx.tx != nil {
+		return pg.tx.tx
+	}
+
+	return pg.Db
+}
+
+// Tx returns the transaction of a PgSQL passed to a RunInTx closure, nil otherwise
+func (pg *PgSQL) Tx() *sql.Tx {
+	if pg.tx == nil || pg.tx.tx == nil {
+		return nil
+	}
+
+	return pg.tx.tx
+}
+
+// Attempt returns the attempt, from 1, of the RunInTx closure pg was passed to, 0 outside RunInTx
+func (pg *PgSQL) Attempt() int {
+	if pg.tx == nil {
+		return 0
+	}
+
+	return pg.tx.attempt
+}
+
+// MarkNonIdempotent records that the RunInTx closure pg was passed to performed a side effect
+// outside the database, such as sending an email, that must not be repeated. RunInTx then
+// returns a serialization failure or deadlock instead of retrying
+func (pg *PgSQL) MarkNonIdempotent() {
+	if pg.tx != nil {
+		pg.tx.nonIdempotent = true
+	}
+}
+
+// RunInTx runs fn in a transaction, passing it a PgSQL bound to the transaction for creating stores,
+// and commits when fn returns nil, otherwise rolls back. The whole transaction is retried, after a
+// jittered exponential backoff, when it fails with a serialization failure or deadlock, unless fn
+// marked a non-idempotent side effect. RunInTx returns the number of retries made. Called on a
+// PgSQL that is already bound to a transaction, fn runs in that transaction and is not retried
+func (pg *PgSQL) RunInTx(ctx context.Context, opts *TxOptions, fn func(ctx context.Context, tx *PgSQL) error) (int, error) {
+	if pg.tx != nil {
+		return 0, fn(ctx, pg)
+	}
+
+	o := TxOptions{Isolation: sql.LevelSerializable}
+	if opts != nil {
+		o = *opts
+	}
+	if o.Retries == 0 {
+		o.Retries = DefaultTxRetries
+	}
+	if o.BaseDelay == 0 {
+		o.BaseDelay = DefaultTxBaseDelay
+	}
+	if o.MaxDelay == 0 {
+		o.MaxDelay = DefaultTxMaxDelay
+	}
+
+	for attempt := 1; ; attempt++ {
+		state, err := pg.runTx(ctx, &o, attempt, fn)
+		if err == nil || !retryable(err) || attempt > o.Retries {
+			return attempt - 1, err
+		}
+
+		if state.nonIdempotent {
+			return attempt - 1, fmt.Errorf("pgsql: not retrying a transaction with non-idempotent side effects: %w", err)
+		}
+
+		if o.OnRetry != nil {
+			o.OnRetry(attempt, err)
+		}
+
+		delay := o.BaseDelay << (attempt - 1)
+		if delay > o.MaxDelay || delay <= 0 {
+			delay = o.MaxDelay
+		}
+
+		timer := time.NewTimer(time.Duration(rand.Int63n(int64(delay)) + 1))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt - 1, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// runTx makes one attempt of a RunInTx transaction
+func (pg *PgSQL) runTx(ctx context.Context, o *TxOptions, attempt int, fn func(ctx context.Context, tx *PgSQL) error) (*txState, error) {
+	if pg.Pool != nil {
+		return pg.runPgxTx(ctx, o, attempt, fn)
+	}
+
+	tx, err := pg.Db.BeginTx(ctx, &sql.TxOptions{Isolation: o.Isolation, ReadOnly: o.ReadOnly})
+	if err != nil {
+		return &txState{attempt: attempt}, err
+	}
+
+	state := &txState{tx: tx, attempt: attempt}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	bound := *pg
+	bound.tx = state
+	if err := fn(ctx, &bound); err != nil {
+		tx.Rollback()
+		return state, err
+	}
+
+	return state, tx.Commit()
+}
+
+// runPgxTx makes one attempt of a RunInTx transaction on pgx
+func (pg *PgSQL) runPgxTx(ctx context.Context, o *TxOptions, attempt int, fn func(ctx context.Context, tx *PgSQL) error) (*txState, error) {
+	txOptions := pgx.TxOptions{IsoLevel: pgxIsolation[o.Isolation]}
+	if o.ReadOnly {
+		txOptions.AccessMode = pgx.ReadOnly
+	}
+
+	tx, err := pg.Pool.BeginTx(ctx, txOptions)
+	if err != nil {
+		return &txState{attempt: attempt}, err
+	}
+
+	state := &txState{pgxTx: tx, attempt: attempt}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback(context.Background())
+			panic(p)
+		}
+	}()
+
+	bound := *pg
+	bound.tx = state
+	if err := fn(ctx, &bound); err != nil {
+		tx.Rollback(context.Background())
+		return state, err
+	}
+
+	return state, tx.Commit(ctx)
+}
+
+// pgxIsolation maps database/sql isolation levels to pgx, sql.LevelDefault to the server default
+var pgxIsolation = map[sql.IsolationLevel]pgx.TxIsoLevel{
+	sql.LevelReadUncommitted: pgx.ReadUncommitted,
+	sql.LevelReadCommitted:   pgx.ReadCommitted,
+	sql.LevelRepeatableRead:  pgx.RepeatableRead,
+	sql.LevelSerializable:    pgx.Serializable,
+}
+
+// retryable reports whether err is a serialization failure or deadlock, or a cached plan that
+// changed result type, whose statement Prepared has already invalidated
+func retryable(err error) bool {
+	if planChanged(err) {
+		return true
+	}
+	err = Classify(err, "")
+
+	return errors.Is(err, ErrSerializationFailure) || errors.Is(err, ErrDeadlock)
+}
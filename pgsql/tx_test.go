@@ -0,0 +1,131 @@
+package pgsql_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+
+	"pggen/pgsql"
+)
+
+// txDriver is a database/sql driver whose connections only begin, commit and roll back
+// transactions, counting them
+type txDriver struct {
+	commits   int
+	rollbacks int
+}
+
+type txConn struct{ d *txDriver }
+
+type txTx struct{ d *txDriver }
+
+func (d *txDriver) Open(name string) (driver.Conn, error) { return &txConn{d}, nil }
+
+func (c *txConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("txDriver: no statements")
+}
+func (c *txConn) Close() error              { return nil }
+func (c *txConn) Begin() (driver.Tx, error) { return &txTx{c.d}, nil }
+func (c *txConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return &txTx{c.d}, nil
+}
+
+func (t *txTx) Commit() error   { t.d.commits++; return nil }
+func (t *txTx) Rollback() error { t.d.rollbacks++; return nil }
+
+func newTxPgSQL(t *testing.T) (*pgsql.PgSQL, *txDriver) {
+	d := &txDriver{}
+	pg := &pgsql.PgSQL{Db: sql.OpenDB(connector{d})}
+	t.Cleanup(func() { pg.Db.Close() })
+
+	return pg, d
+}
+
+type connector struct{ d *txDriver }
+
+func (c connector) Connect(context.Context) (driver.Conn, error) { return &txConn{c.d}, nil }
+func (c connector) Driver() driver.Driver                        { return c.d }
+
+func TestRunInTx(t *testing.T) {
+	pg, d := newTxPgSQL(t)
+	opts := &pgsql.TxOptions{Isolation: sql.LevelSerializable, BaseDelay: time.Microsecond, MaxDelay: time.Millisecond}
+
+	failures := []error{&pq.Error{Code: "40001"}, &pq.Error{Code: "40P01"}}
+	var retried []int
+	opts.OnRetry = func(attempt int, err error) { retried = append(retried, attempt) }
+
+	retries, err := pg.RunInTx(context.Background(), opts, func(ctx context.Context, tx *pgsql.PgSQL) error {
+		if tx.Tx() == nil || tx.Conn() == pg.Conn() {
+			t.Errorf("RunInTx passed a PgSQL not bound to the transaction")
+		}
+
+		if tx.Attempt() <= len(failures) {
+			return failures[tx.Attempt()-1]
+		}
+
+		return nil
+	})
+
+	if err != nil || retries != 2 || len(retried) != 2 || retried[1] != 2 {
+		t.Errorf("RunInTx = %d, %v, retried %v, expected 2 retries", retries, err, retried)
+	}
+
+	if d.commits != 1 || d.rollbacks != 2 {
+		t.Errorf("RunInTx made %d commits and %d rollbacks, expected 1 and 2", d.commits, d.rollbacks)
+	}
+
+	attempts := 0
+	opts.Retries = 3
+	retries, err = pg.RunInTx(context.Background(), opts, func(ctx context.Context, tx *pgsql.PgSQL) error {
+		attempts++
+		return pgsql.Classify(&pq.Error{Code: "40001"}, "public.member")
+	})
+
+	if !errors.Is(err, pgsql.ErrSerializationFailure) || retries != 3 || attempts != 4 {
+		t.Errorf("RunInTx of a failing closure = %d, %v after %d attempts, expected 3 retries", retries, err, attempts)
+	}
+
+	attempts = 0
+	_, err = pg.RunInTx(context.Background(), opts, func(ctx context.Context, tx *pgsql.PgSQL) error {
+		attempts++
+		tx.MarkNonIdempotent()
+		return &pq.Error{Code: "40001"}
+	})
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) || pqErr.Code != "40001" || attempts != 1 {
+		t.Errorf("RunInTx retried a closure with non-idempotent side effects, %d attempts, %v", attempts, err)
+	}
+
+	attempts = 0
+	plain := errors.New("insufficient funds")
+	if _, err = pg.RunInTx(context.Background(), opts, func(ctx context.Context, tx *pgsql.PgSQL) error {
+		attempts++
+		return plain
+	}); err != plain || attempts != 1 {
+		t.Errorf("RunInTx retried a closure failing with %v, %d attempts", err, attempts)
+	}
+}
+
+func TestRunInTxNested(t *testing.T) {
+	pg, d := newTxPgSQL(t)
+
+	_, err := pg.RunInTx(context.Background(), nil, func(ctx context.Context, tx *pgsql.PgSQL) error {
+		_, err := tx.RunInTx(ctx, nil, func(ctx context.Context, inner *pgsql.PgSQL) error {
+			if inner.Tx() != tx.Tx() {
+				t.Errorf("nested RunInTx began another transaction")
+			}
+			return nil
+		})
+		return err
+	})
+
+	if err != nil || d.commits != 1 {
+		t.Errorf("nested RunInTx = %v with %d commits, expected 1", err, d.commits)
+	}
+}
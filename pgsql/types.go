@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,46 +17,156 @@ import (
 // TimeStr for code generation purposes
 //type TimeStr string
 
-// JSONStr for code generation purposes
+// JSONStr for code generation purposes, json columns now map to JSON
 type JSONStr string
 
-// ToGo takes a sql type (string) and returns a go type (string)
-// for use in templating. If no conversion is available an error is returned
+// TypeMapping describes the go representation of a built in postgres base type
+type TypeMapping struct {
+	// OID is the fixed pg_type oid of the type, 0 for extension types whose oid varies by database
+	OID uint32
+	// Name is the type name as reported by udt_name, e.g. int4 or timestamptz
+	Name string
+	// Aliases are the sql spellings of the type as reported by data_type, e.g. integer
+	Aliases []string
+	GoType  string
+	// Import is the package GoType needs, other than time and pggen/pgsql
+	Import string
+	// Elem is the bound type of a range, or the range type of a multirange
+	Elem string
+}
+
+// typeMappings is the registry of postgres types with a go representation. Serial types
+// are their integer type with a default, so they map to signed go types
+var typeMappings = []TypeMapping{
+	{OID: 21, Name: "int2", Aliases: []string{"smallint", "smallserial", "serial2"}, GoType: "int16"},
+	{OID: 23, Name: "int4", Aliases: []string{"integer", "int", "serial", "serial4"}, GoType: "int"},
+	{OID: 20, Name: "int8", Aliases: []string{"bigint", "bigserial", "serial8"}, GoType: "int64"},
+	{OID: 1700, Name: "numeric", Aliases: []string{"decimal"}, GoType: "pgsql.Numeric"},
+	{OID: 700, Name: "float4", Aliases: []string{"real"}, GoType: "float32"},
+	{OID: 701, Name: "float8", Aliases: []string{"double precision", "float"}, GoType: "float64"},
+	{OID: 16, Name: "bool", Aliases: []string{"boolean"}, GoType: "bool"},
+	{OID: 25, Name: "text", GoType: "string"},
+	{OID: 1043, Name: "varchar", Aliases: []string{"character varying"}, GoType: "string"},
+	{OID: 1042, Name: "bpchar", Aliases: []string{"character", "char"}, GoType: "string"},
+	{OID: 18, Name: "char", Aliases: []string{`"char"`}, GoType: "string"},
+	{OID: 19, Name: "name", GoType: "string"},
+	{OID: 17, Name: "bytea", GoType: "[]byte"},
+	{OID: 2950, Name: "uuid", GoType: "uuid.UUID", Import: "github.com/google/uuid"},
+	{OID: 1082, Name: "date", GoType: "time.Time"},
+	{OID: 1083, Name: "time", Aliases: []string{"time without time zone"}, GoType: "time.Time"},
+	{OID: 1266, Name: "timetz", Aliases: []string{"time with time zone"}, GoType: "time.Time"},
+	{OID: 1114, Name: "timestamp", Aliases: []string{"timestamp without time zone"}, GoType: "time.Time"},
+	{OID: 1184, Name: "timestamptz", Aliases: []string{"timestamp with time zone"}, GoType: "time.Time"},
+	{OID: 1186, Name: "interval", GoType: "pgsql.Interval"},
+	{OID: 114, Name: "json", GoType: "pgsql.JSON[any]"},
+	{OID: 3802, Name: "jsonb", GoType: "pgsql.JSON[any]"},
+	{OID: 869, Name: "inet", GoType: "pgsql.Inet"},
+	{OID: 650, Name: "cidr", GoType: "pgsql.Cidr"},
+	{OID: 829, Name: "macaddr", GoType: "pgsql.MacAddr"},
+	{OID: 774, Name: "macaddr8", GoType: "pgsql.MacAddr"},
+	{OID: 600, Name: "point", GoType: "pgsql.Point"},
+	{OID: 601, Name: "lseg", GoType: "pgsql.LSeg"},
+	{OID: 602, Name: "path", GoType: "pgsql.Path"},
+	{OID: 603, Name: "box", GoType: "pgsql.Box"},
+	{OID: 604, Name: "polygon", GoType: "pgsql.Polygon"},
+	{OID: 718, Name: "circle", GoType: "pgsql.Circle"},
+	{OID: 3614, Name: "tsvector", GoType: "pgsql.TSVector"},
+	{OID: 3615, Name: "tsquery", GoType: "pgsql.TSQuery"},
+	{Name: "geometry", GoType: "pgsql.Geometry"},
+	{Name: "geography", GoType: "pgsql.Geometry"},
+	{OID: 3904, Name: "int4range", GoType: "pgsql.Range[int]", Elem: "int4"},
+	{OID: 3926, Name: "int8range", GoType: "pgsql.Range[int64]", Elem: "int8"},
+	{OID: 3906, Name: "numrange", GoType: "pgsql.Range[pgsql.Numeric]", Elem: "numeric"},
+	{OID: 3908, Name: "tsrange", GoType: "pgsql.Range[time.Time]", Elem: "timestamp"},
+	{OID: 3910, Name: "tstzrange", GoType: "pgsql.Range[time.Time]", Elem: "timestamptz"},
+	{OID: 3912, Name: "daterange", GoType: "pgsql.Range[time.Time]", Elem: "date"},
+	{OID: 4451, Name: "int4multirange", GoType: "pgsql.Multirange[int]", Elem: "int4range"},
+	{OID: 4536, Name: "int8multirange", GoType: "pgsql.Multirange[int64]", Elem: "int8range"},
+	{OID: 4532, Name: "nummultirange", GoType: "pgsql.Multirange[pgsql.Numeric]", Elem: "numrange"},
+	{OID: 4533, Name: "tsmultirange", GoType: "pgsql.Multirange[time.Time]", Elem: "tsrange"},
+	{OID: 4534, Name: "tstzmultirange", GoType: "pgsql.Multirange[time.Time]", Elem: "tstzrange"},
+	{OID: 4535, Name: "datemultirange", GoType: "pgsql.Multirange[time.Time]", Elem: "daterange"},
+}
+
+var typesByName = map[string]*TypeMapping{}
+var typesByOID = map[uint32]*TypeMapping{}
+
+func init() {
+	for i := range typeMappings {
+		m := &typeMappings[i]
+		typesByName[m.Name] = m
+		if m.OID != 0 {
+			typesByOID[m.OID] = m
+		}
+	}
+
+	for i := range typeMappings {
+		for _, alias := range typeMappings[i].Aliases {
+			if _, ok := typesByName[alias]; !ok {
+				typesByName[alias] = &typeMappings[i]
+			}
+		}
+	}
+}
+
+// LookupOID returns the registered mapping of the type with the given pg_type oid
+func LookupOID(oid uint32) (TypeMapping, bool) {
+	m, ok := typesByOID[oid]
+	if !ok {
+		return TypeMapping{}, false
+	}
+
+	return *m, true
+}
+
+var typeModifierRegex = regexp.MustCompile(`\s*\(\s*(\d+)\s*(?:,\s*(\d+)\s*)?\)`)
+
+// ToGo takes a sql type (string), either a udt_name such as timestamptz or a sql spelling
+// such as timestamp(3) with time zone, and returns a go type (string) for use in templating.
+// numeric(p) and numeric(p,0) with p up to 18 hold exact integers and map to int64.
+// If no conversion is available an error is returned
 func ToGo(typeStr string) (string, error) {
-	switch typeStr {
-	case "smallint":
-		return "int8", nil
-	case "integer":
-		return "int", nil
-	case "bigint":
-		return "int64", nil
-	case "decimal", "numeric", "double", "double precision":
-		return "float64", nil
-	case "real":
-		return "float32", nil
-	case "smallserial":
-		return "uint8", nil
-	case "serial":
-		return "uint32", nil
-	case "bigserial":
-		return "uint64", nil
-	case "boolean":
-		return "bool", nil
-	case "jsonb":
-		return "pgsql.JSONStr", nil
-	}
-
-	var strTypeRegex = regexp.MustCompile("character varying(\\d+)|varchar(\\d+)|character(\\d+)|char(\\d+)|text")
-	var timeTypeRegex = regexp.MustCompile("((timestamp|time)( \\([0..6]\\))? (with|without) time zone)|date")
+	goType, _, err := lookupType(typeStr)
 
-	switch {
-	case strTypeRegex.MatchString(typeStr):
-		return "string", nil
-	case timeTypeRegex.MatchString(typeStr):
-		return "time.Time", nil
+	return goType, err
+}
+
+// lookupType returns the go type for a sql type as ToGo does, and the import it needs
+func lookupType(typeStr string) (string, string, error) {
+	name := strings.Join(strings.Fields(strings.ToLower(typeStr)), " ")
+
+	var precision, scale int
+	if m := typeModifierRegex.FindStringSubmatch(name); m != nil {
+		precision, _ = strconv.Atoi(m[1])
+		scale, _ = strconv.Atoi(m[2])
+		name = strings.Replace(name, m[0], "", 1)
+	}
+
+	m, ok := typesByName[name]
+	if !ok {
+		return "", "", errors.New("Unknown type")
+	}
+
+	if m.Name == "numeric" && precision > 0 && precision <= 18 && scale == 0 {
+		return "int64", "", nil
+	}
+
+	return m.GoType, m.Import, nil
+}
+
+// typeName returns the type of a column as looked up by ToGo, its udt_name when known,
+// with the declared precision and scale of numeric columns
+func typeName(c *Column) string {
+	name := c.Type
+	if len(c.UDTName) > 0 {
+		name = c.UDTName
 	}
 
-	return "", errors.New("Unknown type")
+	if name == "numeric" && c.Precision > 0 {
+		name = fmt.Sprintf("numeric(%d,%d)", c.Precision, c.Scale)
+	}
+
+	return name
 }
 
 //TimeOnly - strip the error from a time.Time, error tuple
@@ -72,17 +184,98 @@ func DefaultTestValue(typeStr string, index int) string {
 
 	t := time.Now()
 
+	if typeStr == "[]string" {
+		// elements that are quoted or escaped in the array literal, and the text NULL
+		return fmt.Sprintf("[]string{%s, %q, \"\", \"NULL\"}", DefaultTestValue("string", index), `a "quoted", {braced} \ value`)
+	}
+
+	if strings.HasPrefix(typeStr, "[]*") {
+		elem := DefaultTestValue(typeStr[3:], index)
+		if elem == "" || strings.HasPrefix(typeStr[3:], "[]") {
+			return ""
+		}
+
+		// a NULL element after a set one, whose address is taken through a function literal
+		return fmt.Sprintf("%s{func() *%s { v := %s(%s); return &v }(), nil}", typeStr, typeStr[3:], typeStr[3:], elem)
+	}
+
+	if strings.HasPrefix(typeStr, "[]") && typeStr != "[]byte" {
+		elem := DefaultTestValue(typeStr[2:], index)
+		if elem == "" {
+			return ""
+		}
+
+		if strings.HasPrefix(typeStr, "[][]") && typeStr[2:] != "[]byte" {
+			return fmt.Sprintf("%s{%s}", typeStr, strings.TrimPrefix(elem, typeStr[2:]))
+		}
+
+		return fmt.Sprintf("%s{%s, %s}", typeStr, elem, DefaultTestValue(typeStr[2:], index+1))
+	}
+
+	if strings.HasPrefix(typeStr, "pgsql.JSON[") && typeStr != "pgsql.JSON[any]" {
+		return typeStr + "{}"
+	}
+
 	switch typeStr {
-	case "int8", "int", "int64", "uint8", "uint", "uint64":
+	case "int8", "int16", "int32", "int", "int64", "uint8", "uint", "uint64":
 		return fmt.Sprintf("%d", index)
 	case "float64", "float32":
 		return fmt.Sprintf("%f", float32(index))
-	case "boolean":
+	case "pgsql.Range[int]", "pgsql.Range[int64]", "pgsql.Range[pgsql.Numeric]", "pgsql.Range[time.Time]":
+		elem := strings.TrimSuffix(strings.TrimPrefix(typeStr, "pgsql.Range["), "]")
+		// test times are all equal, an inclusive upper bound keeps the range from being empty
+		bounds := "[)"
+		if elem == "time.Time" {
+			bounds = "[]"
+		}
+		return fmt.Sprintf("pgsql.NewRange[%s](%s, %s, \"%s\")", elem, DefaultTestValue(elem, index), DefaultTestValue(elem, index+1), bounds)
+	case "pgsql.Multirange[int]", "pgsql.Multirange[int64]", "pgsql.Multirange[pgsql.Numeric]", "pgsql.Multirange[time.Time]":
+		elem := strings.TrimSuffix(strings.TrimPrefix(typeStr, "pgsql.Multirange["), "]")
+		return fmt.Sprintf("pgsql.Multirange[%s]{%s}", elem, DefaultTestValue("pgsql.Range["+elem+"]", index))
+	case "pgsql.Inet":
+		return fmt.Sprintf("pgsql.Inet{Addr: netip.MustParseAddr(\"192.0.2.%d\")}", index%256)
+	case "pgsql.Cidr":
+		return fmt.Sprintf("pgsql.Cidr{Prefix: netip.MustParsePrefix(\"10.%d.0.0/16\")}", index%256)
+	case "pgsql.MacAddr":
+		return fmt.Sprintf("pgsql.MacAddr{HardwareAddr: []byte{8, 0, 43, 1, 2, %d}}", index%256)
+	case "pgsql.Point":
+		return fmt.Sprintf("pgsql.Point{X: %d, Y: %d.5}", index, index)
+	case "pgsql.LSeg":
+		return fmt.Sprintf("pgsql.LSeg{{X: %d, Y: 0}, {X: %d, Y: 1}}", index, index+1)
+	case "pgsql.Box":
+		// postgres stores the upper right corner first
+		return fmt.Sprintf("pgsql.Box{{X: %d, Y: %d}, {X: %d, Y: %d}}", index+1, index+1, index, index)
+	case "pgsql.Path":
+		return fmt.Sprintf("pgsql.Path{Points: []pgsql.Point{{X: 0, Y: 0}, {X: %d, Y: 1}}}", index+1)
+	case "pgsql.Polygon":
+		return fmt.Sprintf("pgsql.Polygon{{X: 0, Y: 0}, {X: %d, Y: 0}, {X: %d, Y: %d}}", index+1, index+1, index+1)
+	case "pgsql.Circle":
+		return fmt.Sprintf("pgsql.Circle{Center: pgsql.Point{X: %d, Y: %d}, Radius: %d.5}", index, index, index+1)
+	case "pgsql.Geometry":
+		// a WGS 84 point, which suits both geometry(Point,4326) and unconstrained geometry columns
+		return fmt.Sprintf("pgsql.Geometry{SRID: 4326, Shape: pgsql.Point{X: %d.25, Y: %d.5}}", index, index)
+	case "pgsql.TSVector":
+		return fmt.Sprintf("pgsql.TSVector{{Word: \"test%d\", Positions: []pgsql.LexemePosition{{Position: %d, Weight: 'A'}}}}", index, index+1)
+	case "pgsql.TSQuery":
+		return fmt.Sprintf("pgsql.TSQuery(\"'test%d'\")", index)
+	case "pgsql.Interval":
+		return fmt.Sprintf("pgsql.Interval{Months: %d, Days: %d, Microseconds: %d}", index, index+1, index*1000001)
+	case "pgsql.Numeric":
+		return fmt.Sprintf("pgsql.Numeric(\"%d.5\")", index)
+	case "pgsql.NullNumeric":
+		return fmt.Sprintf("pgsql.NullNumeric{Numeric: \"%d.5\", Valid: true}", index)
+	case "bool":
 		return bval
 	case "string":
 		return fmt.Sprintf("\"test %d\"", index)
+	case "[]byte":
+		return fmt.Sprintf("[]byte(\"test %d\")", index)
+	case "uuid.UUID":
+		return fmt.Sprintf("uuid.MustParse(\"%s\")", uuid.New())
 	case "time.Time":
 		return fmt.Sprintf("pgsql.TimeOnly(time.Parse(time.RFC3339,\"%s\"))", t.Format(time.RFC3339))
+	case "pgsql.JSON[any]":
+		return fmt.Sprintf("pgsql.MustJSON[any](`{\"ID\": %d, \"Name\": \"Hello, World\"}`)", 123+index)
 	case "pgsql.JSONStr":
 		jstr, _ := json.Marshal(struct {
 			ID   int
@@ -97,6 +290,44 @@ func DefaultTestValue(typeStr string, index int) string {
 	return ""
 }
 
+// columnTestValue returns the test value of a column, DefaultTestValue of its go type, with the
+// value of a numeric column kept within its declared precision and scale so that it is stored
+// without rounding
+func columnTestValue(c *Column, index int) string {
+	if c.Precision == 0 || (c.GoType != "pgsql.Numeric" && c.GoType != "pgsql.NullNumeric") {
+		return DefaultTestValue(c.GoType, index)
+	}
+
+	// the integer digits left by the scale, at most 9 so the modulus fits an int
+	digits := c.Precision - c.Scale
+	if c.Scale < 0 {
+		digits = c.Precision
+	}
+	if digits > 9 {
+		digits = 9
+	}
+
+	whole := 0
+	if digits > 0 {
+		whole = index % int(math.Pow10(digits))
+	}
+
+	text := strconv.Itoa(whole)
+	switch {
+	case c.Scale > 0:
+		text += ".5"
+	case c.Scale < 0 && whole > 0:
+		// a negative scale rounds to 10^-scale
+		text += strings.Repeat("0", -c.Scale)
+	}
+
+	if c.GoType == "pgsql.NullNumeric" {
+		return fmt.Sprintf("pgsql.NullNumeric{Numeric: %q, Valid: true}", text)
+	}
+
+	return fmt.Sprintf("pgsql.Numeric(%q)", text)
+}
+
 //NewUUID returns a uuid as urn
 func NewUUID() string {
 	uid := uuid.New()
@@ -104,25 +335,280 @@ func NewUUID() string {
 	return uid.URN()
 }
 
+// TestColumns returns the columns assigned values in the struct produced by CreateTestStruct,
+// those without a default that postgres does not assign and whose go type has a test value
+func TestColumns(columns []*Column, tableConstraints []*TableConstraints) []*Column {
+	tested := []*Column{}
+
+	for i, column := range columns {
+		if column.Default == "" && !column.ReadOnly() && columnTestValue(column, i) != "" {
+			tested = append(tested, column)
+		}
+	}
+
+	return tested
+}
+
 // CreateTestStruct produces an anonymous struct with the non-defaulted columns in Column
-// assigned values
+// assigned values, leaving out identity and generated columns
 func CreateTestStruct(columns []*Column, tableConstraints []*TableConstraints) string {
 	var types bytes.Buffer
 	var values bytes.Buffer
 
 	for i, column := range columns {
-		if column.Default == "" {
-			t, _ := ToGo(column.Type)
-			types.WriteString(fmt.Sprintf("%s %s\n", strings.Title(column.Name), t))
+		if column.Default == "" && !column.ReadOnly() {
+			t := column.GoType
+			value := columnTestValue(column, i)
 
 			if t == "string" && IsPrimaryKey(column, tableConstraints) {
-				values.WriteString(fmt.Sprintf("%s: \"%s\",\n", strings.Title(column.Name), NewUUID()))
-			} else {
-				values.WriteString(fmt.Sprintf("%s: %s,\n", strings.Title(column.Name), DefaultTestValue(t, i)))
+				value = fmt.Sprintf("\"%s\"", NewUUID())
 			}
+
+			if value == "" {
+				continue
+			}
+
+			types.WriteString(fmt.Sprintf("%s %s `db:\"%s\"`\n", column.GoName, t, column.Name))
+			values.WriteString(fmt.Sprintf("%s: %s,\n", column.GoName, value))
 		}
 
 	}
 
 	return fmt.Sprintf("struct {\n%s}{\n%s}", string(types.Bytes()), string(values.Bytes()))
 }
+
+// UntestedColumns returns the NOT NULL columns without a default that CreateTestStruct leaves
+// out because their go type has no test value, e.g. composite, domain or @pggen:type columns,
+// for which the generated Create test fails
+func UntestedColumns(columns []*Column, tableConstraints []*TableConstraints) []*Column {
+	untested := []*Column{}
+
+	for i, column := range columns {
+		if column.Nullable || column.Default != "" || column.ReadOnly() {
+			continue
+		}
+
+		if columnTestValue(column, i) == "" && !(column.GoType == "string" && IsPrimaryKey(column, tableConstraints)) {
+			untested = append(untested, column)
+		}
+	}
+
+	return untested
+}
+
+// IsArray reports whether the go type of a column is a slice that must be wrapped by Array
+// to be scanned or bound
+func IsArray(c *Column) bool {
+	return strings.HasPrefix(c.GoType, "[]") && c.GoType != "[]byte"
+}
+
+// ScanArg returns the expression passed to row.Scan for the column field of varname
+func ScanArg(c *Column, varname string) string {
+	if IsArray(c) {
+		return fmt.Sprintf("pgsql.Array(&%s.%s)", varname, c.GoName)
+	}
+
+	return fmt.Sprintf("&%s.%s", varname, c.GoName)
+}
+
+// BindArg returns the expression used as a query argument for the column field of varname
+func BindArg(c *Column, varname string) string {
+	if IsArray(c) {
+		return fmt.Sprintf("pgsql.Array(%s.%s)", varname, c.GoName)
+	}
+
+	return fmt.Sprintf("%s.%s", varname, c.GoName)
+}
+
+// PatchArg returns the expression used as a query argument for the column field of the patch varname
+func PatchArg(c *Column, varname string) string {
+	if IsArray(c) {
+		return fmt.Sprintf("pgsql.Array(%s.%s.Value)", varname, c.GoName)
+	}
+
+	return fmt.Sprintf("%s.%s.Value", varname, c.GoName)
+}
+
+// RangeColumn describes a range or multirange column for generating its filter helpers
+type RangeColumn struct {
+	*Column
+	// ElemGoType and ElemType are the go and sql types of the range bounds, e.g. time.Time and timestamptz
+	ElemGoType string
+	ElemType   string
+	// RangeGoType and RangeType are the go and sql types of a single range, e.g. pgsql.Range[time.Time] and tstzrange
+	RangeGoType string
+	RangeType   string
+}
+
+// RangeColumns returns the columns of built in range and multirange types, other than those
+// given another go type by an annotation or override
+func RangeColumns(columns []*Column) []*RangeColumn {
+	ranges := []*RangeColumn{}
+
+	for _, c := range columns {
+		m, ok := typesByName[typeName(c)]
+		if !ok || len(m.Elem) == 0 || m.GoType != c.GoType {
+			continue
+		}
+
+		rc := &RangeColumn{Column: c, RangeGoType: m.GoType, RangeType: m.Name}
+		if strings.HasPrefix(m.GoType, "pgsql.Multirange[") {
+			r := typesByName[m.Elem]
+			rc.RangeGoType, rc.RangeType = r.GoType, r.Name
+		}
+
+		elem := typesByName[typesByName[rc.RangeType].Elem]
+		rc.ElemGoType, rc.ElemType = elem.GoType, elem.Name
+
+		ranges = append(ranges, rc)
+	}
+
+	return ranges
+}
+
+// SearchColumn describes a tsvector column for generating its full text Search method
+type SearchColumn struct {
+	*Column
+	// Method is the generated method name, Search, or Search followed by the go field name
+	// when the table has more than one tsvector column
+	Method string
+	// Headline is the text column ts_headline snippets are taken from, empty for none
+	Headline string
+	// ConfigArg is the quoted text search configuration argument, e.g. 'english',
+	// or empty to use the database default
+	ConfigArg string
+}
+
+// SearchColumns returns the tsvector columns, other than those given another go type by an
+// annotation or override, with warnings for @pggen:headline annotations naming no text column
+func SearchColumns(columns []*Column) ([]*SearchColumn, []string) {
+	search := []*SearchColumn{}
+	warnings := []string{}
+
+	for _, c := range columns {
+		if c.GoType == "pgsql.TSVector" {
+			search = append(search, &SearchColumn{Column: c, Method: "Search"})
+		}
+	}
+
+	for _, sc := range search {
+		if len(search) > 1 {
+			sc.Method += sc.GoName
+		}
+
+		if len(sc.Annotations.TSConfig) > 0 {
+			sc.ConfigArg = "'" + sc.Annotations.TSConfig + "', "
+		}
+
+		if len(sc.Annotations.Headline) == 0 {
+			continue
+		}
+
+		for _, c := range columns {
+			if c.Name == sc.Annotations.Headline && c.GoType == "string" {
+				sc.Headline = c.Name
+			}
+		}
+
+		if len(sc.Headline) == 0 {
+			warnings = append(warnings, fmt.Sprintf("column %s: @pggen:headline=%s does not name a text column, Search returns no headlines", sc.Name, sc.Annotations.Headline))
+		}
+	}
+
+	return search, warnings
+}
+
+// VersionColumn describes the column generated Update, Patch and Delete methods compare for
+// optimistic concurrency, failing with a *StaleObjectError when the row no longer has the version read
+type VersionColumn struct {
+	*Column
+	// Bump is set for an integer column, incremented by the generated Update and Patch methods.
+	// The xmin system column is advanced by postgres itself
+	Bump bool
+}
+
+// versionGoTypes are the go types of the integer columns accepted as version columns
+var versionGoTypes = map[string]bool{"int16": true, "int32": true, "int64": true, "int": true}
+
+// TableVersion returns the version column of table, named by a @pggen:version=column annotation
+// on the table or a @pggen:version annotation on one of its integer columns, nil when it has none.
+// @pggen:version=xmin appends the read only xmin system column to the returned columns. Warnings
+// report annotations naming no integer column, which leave the table unversioned
+func TableVersion(table *Table, columns []*Column) (*VersionColumn, []*Column, []string) {
+	warnings := []string{}
+	name := table.Annotations.VersionColumn
+
+	for _, c := range columns {
+		if !c.Annotations.Version {
+			continue
+		}
+
+		if len(name) > 0 && name != c.Name {
+			warnings = append(warnings, fmt.Sprintf("column %s: @pggen:version ignored, the version column is %s", c.Name, name))
+			continue
+		}
+		name = c.Name
+	}
+
+	if len(name) == 0 {
+		return nil, columns, warnings
+	}
+
+	if name == "xmin" {
+		xmin := &Column{Name: "xmin", Type: "xid", UDTName: "xid", GoName: "Xmin", GoType: "uint32", IsGenerated: true,
+			Doc: "Xmin is the id of the transaction that last wrote the row, its version"}
+		return &VersionColumn{Column: xmin}, append(columns[:len(columns):len(columns)], xmin), warnings
+	}
+
+	for _, c := range columns {
+		if c.Name == name && versionGoTypes[c.GoType] && !c.ReadOnly() {
+			return &VersionColumn{Column: c, Bump: true}, columns, warnings
+		}
+	}
+
+	warnings = append(warnings, fmt.Sprintf("@pggen:version=%s does not name a writable integer column, Update and Delete are not versioned", name))
+
+	return nil, columns, warnings
+}
+
+// UpdateColumns returns the writable columns assigned by generated Update methods, other than
+// the version column, which Update compares and increments instead
+func UpdateColumns(columns []*Column, version *VersionColumn) []*Column {
+	update := []*Column{}
+
+	for _, c := range WritableColumns(columns) {
+		if version == nil || c != version.Column {
+			update = append(update, c)
+		}
+	}
+
+	return update
+}
+
+// SpatialColumns returns the PostGIS geometry columns, other than those given another go type
+// by an annotation or override, for generating their spatial filter helpers
+func SpatialColumns(columns []*Column) []*Column {
+	spatial := []*Column{}
+
+	for _, c := range columns {
+		if c.GoType == "pgsql.Geometry" && c.UDTName == "geometry" {
+			spatial = append(spatial, c)
+		}
+	}
+
+	return spatial
+}
+
+// NetworkColumns returns the inet and cidr columns, other than those given another go type
+// by an annotation or override, for generating their network operator filter helpers
+func NetworkColumns(columns []*Column) []*Column {
+	network := []*Column{}
+
+	for _, c := range columns {
+		if c.GoType == "pgsql.Inet" || c.GoType == "pgsql.Cidr" {
+			network = append(network, c)
+		}
+	}
+
+	return network
+}
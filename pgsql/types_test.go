@@ -0,0 +1,272 @@
+package pgsql_test
+
+import (
+	"strings"
+	"testing"
+
+	"pggen/pgsql"
+)
+
+func TestToGo(t *testing.T) {
+	mappings := []struct {
+		sqlType string
+		goType  string
+	}{
+		{"int2", "int16"},
+		{"smallint", "int16"},
+		{"smallserial", "int16"},
+		{"int4", "int"},
+		{"integer", "int"},
+		{"serial", "int"},
+		{"int8", "int64"},
+		{"bigint", "int64"},
+		{"bigserial", "int64"},
+		{"numeric", "pgsql.Numeric"},
+		{"decimal", "pgsql.Numeric"},
+		{"numeric(10,2)", "pgsql.Numeric"},
+		{"numeric(30)", "pgsql.Numeric"},
+		{"numeric(12,0)", "int64"},
+		{"numeric(18)", "int64"},
+		{"float4", "float32"},
+		{"real", "float32"},
+		{"float8", "float64"},
+		{"double precision", "float64"},
+		{"bool", "bool"},
+		{"boolean", "bool"},
+		{"text", "string"},
+		{"varchar", "string"},
+		{"character varying", "string"},
+		{"character varying(20)", "string"},
+		{"bpchar", "string"},
+		{"character(3)", "string"},
+		{"\"char\"", "string"},
+		{"name", "string"},
+		{"bytea", "[]byte"},
+		{"uuid", "uuid.UUID"},
+		{"date", "time.Time"},
+		{"time", "time.Time"},
+		{"time without time zone", "time.Time"},
+		{"timetz", "time.Time"},
+		{"time(3) with time zone", "time.Time"},
+		{"timestamp", "time.Time"},
+		{"timestamp without time zone", "time.Time"},
+		{"timestamptz", "time.Time"},
+		{"timestamp with time zone", "time.Time"},
+		{"timestamp(3) with time zone", "time.Time"},
+		{"interval", "pgsql.Interval"},
+		{"interval(3)", "pgsql.Interval"},
+		{"json", "pgsql.JSON[any]"},
+		{"jsonb", "pgsql.JSON[any]"},
+		{"inet", "pgsql.Inet"},
+		{"cidr", "pgsql.Cidr"},
+		{"macaddr", "pgsql.MacAddr"},
+		{"macaddr8", "pgsql.MacAddr"},
+		{"point", "pgsql.Point"},
+		{"lseg", "pgsql.LSeg"},
+		{"path", "pgsql.Path"},
+		{"box", "pgsql.Box"},
+		{"polygon", "pgsql.Polygon"},
+		{"circle", "pgsql.Circle"},
+		{"tsvector", "pgsql.TSVector"},
+		{"tsquery", "pgsql.TSQuery"},
+		{"geometry", "pgsql.Geometry"},
+		{"geography", "pgsql.Geometry"},
+		{"int4range", "pgsql.Range[int]"},
+		{"int8range", "pgsql.Range[int64]"},
+		{"numrange", "pgsql.Range[pgsql.Numeric]"},
+		{"tsrange", "pgsql.Range[time.Time]"},
+		{"tstzrange", "pgsql.Range[time.Time]"},
+		{"daterange", "pgsql.Range[time.Time]"},
+		{"int4multirange", "pgsql.Multirange[int]"},
+		{"int8multirange", "pgsql.Multirange[int64]"},
+		{"nummultirange", "pgsql.Multirange[pgsql.Numeric]"},
+		{"tsmultirange", "pgsql.Multirange[time.Time]"},
+		{"tstzmultirange", "pgsql.Multirange[time.Time]"},
+		{"datemultirange", "pgsql.Multirange[time.Time]"},
+	}
+
+	for _, m := range mappings {
+		t.Run(m.sqlType, func(t *testing.T) {
+			goType, err := pgsql.ToGo(m.sqlType)
+			if err != nil {
+				t.Fatalf("ToGo(%q) returned error %s", m.sqlType, err)
+			}
+
+			if goType != m.goType {
+				t.Errorf("ToGo(%q) = %s, expected %s", m.sqlType, goType, m.goType)
+			}
+		})
+	}
+}
+
+func TestToGoUnknown(t *testing.T) {
+	if goType, err := pgsql.ToGo("pg_lsn"); err == nil {
+		t.Errorf("ToGo of an unknown type returned %s, expected an error", goType)
+	}
+}
+
+func TestLookupOID(t *testing.T) {
+	oids := map[uint32]string{21: "int2", 23: "int4", 20: "int8", 1700: "numeric", 25: "text", 1184: "timestamptz", 1186: "interval", 600: "point", 604: "polygon"}
+
+	for oid, name := range oids {
+		m, ok := pgsql.LookupOID(oid)
+		if !ok || m.Name != name {
+			t.Errorf("LookupOID(%d) = %s, expected %s", oid, m.Name, name)
+		}
+	}
+
+	if _, ok := pgsql.LookupOID(0); ok {
+		t.Errorf("LookupOID(0) found a mapping")
+	}
+}
+
+func TestNumeric(t *testing.T) {
+	scans := map[string]pgsql.Numeric{
+		"12.50":  "12.5",
+		"12.00":  "12",
+		"-0.00":  "0",
+		"100":    "100",
+		"NaN":    "NaN",
+		"0.0001": "0.0001",
+	}
+
+	for text, expected := range scans {
+		var n pgsql.Numeric
+		if err := n.Scan([]byte(text)); err != nil {
+			t.Fatalf("Scan(%q) returned error %s", text, err)
+		}
+
+		if n != expected {
+			t.Errorf("Scan(%q) = %s, expected %s", text, n, expected)
+		}
+	}
+
+	r, ok := pgsql.Numeric("12345678901234567890.125").Rat()
+	if !ok || r.FloatString(3) != "12345678901234567890.125" {
+		t.Errorf("Rat lost precision: %v", r)
+	}
+
+	if v, err := pgsql.Numeric("").Value(); err != nil || v != "0" {
+		t.Errorf("Value of the zero Numeric = %v, expected 0", v)
+	}
+
+	null := pgsql.NullNumeric{Numeric: "1", Valid: true}
+	if err := null.Scan(nil); err != nil || null.Valid {
+		t.Fatalf("Scan(nil) = %+v, %v, expected an invalid NullNumeric", null, err)
+	}
+
+	if v, err := null.Value(); err != nil || v != nil {
+		t.Errorf("Value of a NULL NullNumeric = %v, %v, expected NULL", v, err)
+	}
+
+	var zero pgsql.NullNumeric
+	if err := zero.Scan([]byte("0.00")); err != nil || !zero.Valid || zero.Numeric != "0" {
+		t.Fatalf("Scan(0.00) = %+v, %v, expected a valid 0", zero, err)
+	}
+
+	if v, err := zero.Value(); err != nil || v != "0" {
+		t.Errorf("Value of a valid zero NullNumeric = %v, %v, expected 0", v, err)
+	}
+
+	if v, err := pgsql.NewRange(pgsql.NullNumeric{}, pgsql.NullNumeric{Numeric: "5", Valid: true}, "[)").Value(); err == nil {
+		t.Errorf("Value of a range with a NULL bound = %v, expected an error", v)
+	}
+}
+
+func TestTableVersion(t *testing.T) {
+	columns := []*pgsql.Column{
+		{Name: "id", GoName: "ID", GoType: "int32"},
+		{Name: "name", GoName: "Name", GoType: "string"},
+		{Name: "version", GoName: "Version", GoType: "int32", Annotations: pgsql.Annotations{Version: true}},
+	}
+
+	version, versioned, warnings := pgsql.TableVersion(&pgsql.Table{Name: "member"}, columns)
+	if version == nil || version.Column != columns[2] || !version.Bump || len(versioned) != 3 || len(warnings) != 0 {
+		t.Fatalf("TableVersion of an annotated column = %+v, %v", version, warnings)
+	}
+
+	if update := pgsql.UpdateColumns(columns[1:], version); len(update) != 1 || update[0].Name != "name" {
+		t.Errorf("UpdateColumns = %v, expected the version column left out", update)
+	}
+
+	xmin := &pgsql.Table{Name: "site", Annotations: pgsql.Annotations{VersionColumn: "xmin"}}
+	version, versioned, warnings = pgsql.TableVersion(xmin, columns[:2])
+	if version == nil || version.Bump || version.GoType != "uint32" || len(versioned) != 3 || !versioned[2].ReadOnly() || len(warnings) != 0 {
+		t.Fatalf("TableVersion of xmin = %+v, %v, %v", version, versioned, warnings)
+	}
+
+	named := &pgsql.Table{Name: "site", Annotations: pgsql.Annotations{VersionColumn: "name"}}
+	if version, _, warnings = pgsql.TableVersion(named, columns); version != nil || len(warnings) != 2 {
+		t.Errorf("TableVersion naming a text column = %+v, %v, expected no version and warnings", version, warnings)
+	}
+
+	if _, a, _ := pgsql.ParseAnnotations("@pggen:version=xmin"); a.VersionColumn != "xmin" || a.Version {
+		t.Errorf("ParseAnnotations of a table version = %+v", a)
+	}
+}
+
+func TestUntestedColumns(t *testing.T) {
+	columns := []*pgsql.Column{
+		{Name: "id", GoName: "ID", GoType: "int", Default: "nextval('x')"},
+		{Name: "name", GoName: "Name", GoType: "string"},
+		{Name: "home", GoName: "Home", GoType: "Address"},
+		{Name: "price", GoName: "Price", GoType: "money.Money", Nullable: true},
+		{Name: "words", GoName: "Words", GoType: "pgsql.TSVector", IsGenerated: true},
+	}
+
+	untested := pgsql.UntestedColumns(columns, nil)
+	if len(untested) != 1 || untested[0].Name != "home" {
+		t.Errorf("UntestedColumns = %v, expected the NOT NULL composite column home", untested)
+	}
+}
+
+func TestResolveArrayElem(t *testing.T) {
+	r := &pgsql.Resolver{Schema: "public", PackageRoot: "example.com/db", Domains: map[string]*pgsql.Domain{
+		"shared.email": {Schema: "shared", Name: "email", GoName: "Email"},
+	}}
+
+	tests := []struct {
+		column *pgsql.Column
+		goType string
+		imp    string
+	}{
+		{&pgsql.Column{Name: "mails", Type: "ARRAY", UDTSchema: "public", ElemSchema: "shared", ElemName: "email", ElemKind: "d"}, "[]shared.Email", "example.com/db/shared"},
+		{&pgsql.Column{Name: "stops", Type: "ARRAY", UDTSchema: "public", ElemSchema: "shared", ElemName: "address", ElemKind: "c"}, "[]shared.Address", "example.com/db/shared"},
+		{&pgsql.Column{Name: "homes", Type: "ARRAY", UDTSchema: "shared", ElemSchema: "public", ElemName: "address", ElemKind: "c"}, "[]Address", ""},
+		{&pgsql.Column{Name: "tags", Type: "ARRAY", UDTSchema: "pg_catalog", ElemSchema: "pg_catalog", ElemName: "text", ElemKind: "b"}, "[]string", ""},
+	}
+
+	for _, tt := range tests {
+		if _, err := r.ResolveColumn(tt.column); err != nil {
+			t.Errorf("ResolveColumn(%s) returned error %s", tt.column.Name, err)
+			continue
+		}
+
+		if tt.column.GoType != tt.goType || tt.column.Import != tt.imp {
+			t.Errorf("ResolveColumn(%s) = %s, %q, expected %s, %q", tt.column.Name, tt.column.GoType, tt.column.Import, tt.goType, tt.imp)
+		}
+	}
+}
+
+func TestCreateTestStructNumeric(t *testing.T) {
+	columns := []*pgsql.Column{
+		{Name: "amount", GoName: "Amount", GoType: "pgsql.Numeric"},
+		{Name: "whole", GoName: "Whole", GoType: "pgsql.Numeric", Precision: 30},
+		{Name: "price", GoName: "Price", GoType: "pgsql.Numeric", Precision: 5, Scale: 2},
+		{Name: "rate", GoName: "Rate", GoType: "pgsql.NullNumeric", Precision: 3, Scale: 3, Nullable: true},
+		{Name: "round", GoName: "Round", GoType: "pgsql.Numeric", Precision: 2, Scale: -3},
+	}
+
+	s := pgsql.CreateTestStruct(columns, nil)
+	for _, value := range []string{
+		`Amount: pgsql.Numeric("0.5")`,
+		`Whole: pgsql.Numeric("1")`,
+		`Price: pgsql.Numeric("2.5")`,
+		`Rate: pgsql.NullNumeric{Numeric: "0.5", Valid: true}`,
+		`Round: pgsql.Numeric("4000")`,
+	} {
+		if !strings.Contains(s, value) {
+			t.Errorf("CreateTestStruct = %s, expected %s", s, value)
+		}
+	}
+}
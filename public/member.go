@@ -1,20 +1,19 @@
 package public
 
 import (
+	"context"
 	"fmt"
+	"iter"
 	"pggen/pgsql"
-	"reflect"
-	"time"
 )
 
 // Member models the table public.member
 type Member struct {
-	pgSQL     *pgsql.PgSQL
-	Id        int
-	Firstname string
-	Lastname  string
-	Email     string
-	Password  string
+	Id        int    `db:"id"`
+	Firstname string `db:"firstname"`
+	Lastname  string `db:"lastname"`
+	Email     string `db:"email"`
+	Password  string `db:"password"`
 }
 
 // MemberPrimaryKey models the primary key for the table public.member
@@ -22,86 +21,166 @@ type MemberPrimaryKey struct {
 	Id int
 }
 
-// NewMember instantiates and returns a Member struct
-func NewMember(pgSQL *pgsql.PgSQL) *Member {
-	s := new(Member)
-	s.pgSQL = pgSQL
+// MemberPatch holds the columns of a partial update of the table public.member,
+// only the fields that are set are updated
+type MemberPatch struct {
+	Firstname pgsql.Optional[string]
+	Lastname  pgsql.Optional[string]
+	Email     pgsql.Optional[string]
+	Password  pgsql.Optional[string]
+}
+
+// MemberStore reads and writes the rows of the table public.member. It holds no row
+// state, every method returns new Member values, so a store is safe for concurrent use
+type MemberStore struct {
+	pgSQL *pgsql.PgSQL
+}
 
-	return s
+// NewMemberStore instantiates and returns a MemberStore
+func NewMemberStore(pgSQL *pgsql.PgSQL) *MemberStore {
+	return &MemberStore{pgSQL: pgSQL}
 }
 
 // Create inserts a Member record into the public.member table
 // using the values of the interface argument as an initializer
-func (member *Member) Create(s interface{}) (*MemberPrimaryKey, error) {
-	if err := member.pgSQL.Db.Ping(); err != nil {
-		return nil, err
+// and returns the inserted row, with the values postgres assigned.
+// Constraint violations are returned as the typed errors of pgsql.Classify
+func (store *MemberStore) Create(ctx context.Context, s interface{}) (*Member, error) {
+	insertStmt := fmt.Sprintf("%s %s", pgsql.InsertClause(s, "member"), "returning id, firstname, lastname, email, password")
+
+	row := store.pgSQL.Prepared().QueryRowContext(ctx, insertStmt, pgsql.FieldValues(s)...)
+	created := new(Member)
+	if err := row.Scan(&created.Id, &created.Firstname, &created.Lastname, &created.Email, &created.Password); err != nil {
+		return nil, pgsql.Classify(err, "public.member")
 	}
 
-	insertStmt := fmt.Sprintf("%s %s", pgsql.InsertClause(s, "member"), "returning id")
-
-	v := reflect.ValueOf(s)
-	n := v.NumField()
-	ifs := make([]interface{}, n)
-
-	for i := 0; i < n; i++ {
-		switch v.Field(i).Kind() {
-		case reflect.Int8, reflect.Int, reflect.Int64:
-			ifs[i] = v.Field(i).Int()
-		case reflect.Uint8, reflect.Uint, reflect.Uint64:
-			ifs[i] = v.Field(i).Uint()
-		case reflect.Float64, reflect.Float32:
-			ifs[i] = v.Field(i).Float()
-		case reflect.Bool:
-			ifs[i] = v.Field(i).Bool()
-		case reflect.String:
-			ifs[i] = v.Field(i).String()
-		case reflect.Struct:
-			ifs[i] = v.Field(i).MethodByName("Format").Call([]reflect.Value{reflect.ValueOf(time.RFC3339)})[0].String()
-		}
-	}
+	return created, nil
+}
 
-	row := member.pgSQL.Db.QueryRow(insertStmt, ifs...)
-	pk := new(MemberPrimaryKey)
-	err := row.Scan(&pk.Id)
+// Read selects the  public.member row keyed by  MemberPrimaryKey and returns a *Member, error tuple
+// A *pgsql.NotFoundError is returned when no row matched
+func (store *MemberStore) Read(ctx context.Context, pk *MemberPrimaryKey) (*Member, error) {
+	return store.ReadQuery(pk).Run(ctx, store.pgSQL)
+}
 
-	return pk, err
+// ReadQuery returns the query run by Read, to queue on a pgsql.Batch with other queries
+func (store *MemberStore) ReadQuery(pk *MemberPrimaryKey) *pgsql.Query[*Member] {
+	selectStmt := "select id, firstname, lastname, email, password from member where id = $1"
+	args := []interface{}{pk.Id}
+
+	return pgsql.RowQuery("public.member", selectStmt, args, scanMember)
 }
 
-// Read selects the  public.member row keyed by  MemberPrimaryKey and returns a *Member, error tuple
-func (member *Member) Read(pk *MemberPrimaryKey) (*Member, error) {
-	if err := member.pgSQL.Db.Ping(); err != nil {
+// scanMember scans a row of all the columns of public.member
+func scanMember(row pgsql.Row) (*Member, error) {
+	item := new(Member)
+	if err := row.Scan(&item.Id, &item.Firstname, &item.Lastname, &item.Email, &item.Password); err != nil {
 		return nil, err
 	}
 
-	selectStmt := "select id, firstname, lastname, email, password from member where id = $1"
+	return item, nil
+}
 
-	row := member.pgSQL.Db.QueryRow(selectStmt, pk.Id)
+// Update updates the row of the public.member table represented by the Member argument
+// and returns the updated row and the number of rows affected. A *pgsql.NotFoundError is returned when no row matched
+func (store *MemberStore) Update(ctx context.Context, s *Member) (*Member, int64, error) {
+	updateStmt := "update member set firstname = $1, lastname = $2, email = $3, password = $4 where id = $5 " +
+		"returning id, firstname, lastname, email, password"
+	rows, err := store.pgSQL.Prepared().QueryContext(ctx, updateStmt, s.Firstname, s.Lastname, s.Email, s.Password, s.Id)
+	if err != nil {
+		return nil, 0, pgsql.Classify(err, "public.member")
+	}
+	defer rows.Close()
+
+	// each updated row is returned, their number is the number of rows affected
+	updated := new(Member)
+	var n int64
+	for rows.Next() {
+		if err := rows.Scan(&updated.Id, &updated.Firstname, &updated.Lastname, &updated.Email, &updated.Password); err != nil {
+			return nil, 0, pgsql.Classify(err, "public.member")
+		}
+		n++
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, pgsql.Classify(err, "public.member")
+	}
 
-	err := row.Scan(&member.Id, &member.Firstname, &member.Lastname, &member.Email, &member.Password)
+	if n == 0 {
+		return nil, 0, &pgsql.NotFoundError{Table: "public.member"}
+	}
 
-	return member, err
+	return updated, n, nil
 }
 
-// Update upates the row of the public.member table represented by the Member argument
-func (member *Member) Update(s *Member) error {
-	if err := member.pgSQL.Db.Ping(); err != nil {
-		return err
+// Patch updates only the columns set in patch of the public.member row keyed by pk, leaving
+// concurrent updates of other columns in place, and returns the updated row. A patch setting no column
+// returns pgsql.ErrEmptyPatch, a *pgsql.NotFoundError is returned when no row matched
+func (store *MemberStore) Patch(ctx context.Context, pk *MemberPrimaryKey, patch MemberPatch) (*Member, error) {
+	var set pgsql.Assignments
+	if patch.Firstname.Set {
+		set.Add("firstname", patch.Firstname.Value)
+	}
+	if patch.Lastname.Set {
+		set.Add("lastname", patch.Lastname.Value)
+	}
+	if patch.Email.Set {
+		set.Add("email", patch.Email.Value)
+	}
+	if patch.Password.Set {
+		set.Add("password", patch.Password.Value)
+	}
+	if set.Len() == 0 {
+		return nil, pgsql.ErrEmptyPatch
 	}
 
-	updateStmt := "update member set firstname = $1, lastname = $2, email = $3, password = $4 where id = $1"
-	_, err := member.pgSQL.Db.Exec(updateStmt, s.Firstname, s.Lastname, s.Email, s.Password, s.Id)
+	where := pgsql.Filter{Expr: "id = ?", Args: []interface{}{pk.Id}}
+	patchStmt := "update member set " + set.Clause() + where.Where(set.Len()+1) + " returning id, firstname, lastname, email, password"
 
-	return err
+	return pgsql.DynamicRowQuery("public.member", patchStmt, append(set.Args(), where.Args...), scanMember).Run(ctx, store.pgSQL)
 }
 
-// Delete removes the Member row from the database
-func (member *Member) Delete(pk *MemberPrimaryKey) error {
-	if err := member.pgSQL.Db.Ping(); err != nil {
-		return err
+// Delete removes the Member row from the database and returns the number of rows affected.
+// A *pgsql.NotFoundError is returned when no row matched
+func (store *MemberStore) Delete(ctx context.Context, pk *MemberPrimaryKey) (int64, error) {
+	deleteStmt := "delete from member  where id = $1"
+	result, err := store.pgSQL.Prepared().ExecContext(ctx, deleteStmt, pk.Id)
+	if err != nil {
+		return 0, pgsql.Classify(err, "public.member")
 	}
 
-	deleteStmt := "delete from member  where id = $1"
-	_, err := member.pgSQL.Db.Exec(deleteStmt, pk.Id)
+	n, err := result.RowsAffected()
+	if err == nil && n == 0 {
+		err = &pgsql.NotFoundError{Table: "public.member"}
+	}
+
+	return n, err
+}
+
+// List selects the public.member rows matching filter, ordered and paged by opts
+func (store *MemberStore) List(ctx context.Context, filter pgsql.Filter, opts pgsql.ListOptions) ([]*Member, error) {
+	return store.ListQuery(filter, opts).Run(ctx, store.pgSQL)
+}
+
+// ListQuery returns the query run by List, to queue on a pgsql.Batch with other queries
+func (store *MemberStore) ListQuery(filter pgsql.Filter, opts pgsql.ListOptions) *pgsql.Query[[]*Member] {
+	selectStmt := "select id, firstname, lastname, email, password from member" + filter.Where(1) + opts.Clause(len(filter.Args)+1)
+
+	return pgsql.RowsQuery("public.member", selectStmt, opts.Args(filter.Args), scanMember)
+}
+
+// Iterate calls fn with each public.member row matching filter as it is read, without loading them
+// all, and stops at the first error fn returns. Give pgsql.WithCursor to read the rows through a server side cursor
+func (store *MemberStore) Iterate(ctx context.Context, filter pgsql.Filter, fn func(Member) error, opts ...pgsql.IterOption) error {
+	selectStmt := "select id, firstname, lastname, email, password from member" + filter.Where(1)
+
+	return pgsql.Iterate(ctx, store.pgSQL, "public.member", selectStmt, filter.Args, scanMember, fn, opts...)
+}
+
+// All returns an iterator over the public.member rows matching filter, read as by Iterate.
+// An error ends the iteration
+func (store *MemberStore) All(ctx context.Context, filter pgsql.Filter, opts ...pgsql.IterOption) iter.Seq2[Member, error] {
+	selectStmt := "select id, firstname, lastname, email, password from member" + filter.Where(1)
 
-	return err
+	return pgsql.All(ctx, store.pgSQL, "public.member", selectStmt, filter.Args, scanMember, opts...)
 }
@@ -1,10 +1,11 @@
 package public_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"pggen/pgsql"
 	. "pggen/public"
-	"reflect"
 	"testing"
 )
 
@@ -14,7 +15,7 @@ type memberDbConnection struct {
 
 var memberconn memberDbConnection
 
-func memberSetup(t *testing.T) {
+func memberSetup(t testing.TB) {
 	fmt.Println("Running setup")
 	if memberconn.PgSQL == nil {
 		connectionStr := "database=touch user=touch_dbuser password=verified_touch_user_001 host=192.168.0.107"
@@ -30,13 +31,14 @@ func memberSetup(t *testing.T) {
 func TestPublicMember(t *testing.T) {
 	memberSetup(t)
 
-	member := NewMember(memberconn.PgSQL)
+	ctx := context.Background()
+	store := NewMemberStore(memberconn.PgSQL)
 
 	s := struct {
-		Firstname string
-		Lastname  string
-		Email     string
-		Password  string
+		Firstname string `db:"firstname"`
+		Lastname  string `db:"lastname"`
+		Email     string `db:"email"`
+		Password  string `db:"password"`
 	}{
 		Firstname: "test 1",
 		Lastname:  "test 2",
@@ -44,24 +46,204 @@ func TestPublicMember(t *testing.T) {
 		Password:  "test 4",
 	}
 
-	pk, err := member.Create(s)
+	created, err := store.Create(ctx, s)
 
 	if err != nil {
 		t.Fatalf("\nError from Create row for %s\n%s\n", "member", err)
 	}
 
-	returnedVal, err := member.Read(pk)
+	pk := &MemberPrimaryKey{Id: created.Id}
+
+	returnedVal, err := store.Read(ctx, pk)
 	if err != nil {
 		t.Fatalf("\nError from Read row for %s\n%s\n", "member", err)
 	}
 
-	if !reflect.DeepEqual(returnedVal, member) {
-		t.Errorf("Failed equivalency for returnedVal and %s", "member")
+	if !pgsql.Equal(created.Firstname, s.Firstname) {
+		t.Errorf("Failed equivalency for created.%s", "Firstname")
+	}
+
+	if !pgsql.Equal(returnedVal.Firstname, s.Firstname) {
+		t.Errorf("Failed equivalency for returnedVal.%s", "Firstname")
+	}
+
+	if !pgsql.Equal(created.Lastname, s.Lastname) {
+		t.Errorf("Failed equivalency for created.%s", "Lastname")
+	}
+
+	if !pgsql.Equal(returnedVal.Lastname, s.Lastname) {
+		t.Errorf("Failed equivalency for returnedVal.%s", "Lastname")
+	}
+
+	if !pgsql.Equal(created.Email, s.Email) {
+		t.Errorf("Failed equivalency for created.%s", "Email")
+	}
+
+	if !pgsql.Equal(returnedVal.Email, s.Email) {
+		t.Errorf("Failed equivalency for returnedVal.%s", "Email")
+	}
+
+	if !pgsql.Equal(created.Password, s.Password) {
+		t.Errorf("Failed equivalency for created.%s", "Password")
+	}
+
+	if !pgsql.Equal(returnedVal.Password, s.Password) {
+		t.Errorf("Failed equivalency for returnedVal.%s", "Password")
+	}
+
+	reads := make(chan *Member)
+	for i := 0; i < 4; i++ {
+		go func() {
+			read, err := store.Read(ctx, pk)
+			if err != nil {
+				t.Errorf("\nError from concurrent Read row for %s\n%s\n", "member", err)
+			}
+			reads <- read
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		read := <-reads
+		if read != nil && !pgsql.Equal(read.Id, pk.Id) {
+			t.Errorf("Failed equivalency for concurrently read.%s", "Id")
+		}
+	}
+
+	batch := memberconn.PgSQL.NewBatch()
+	readQuery := store.ReadQuery(pk)
+	listQuery := store.ListQuery(pgsql.Filter{}, pgsql.ListOptions{Limit: 1})
+	batch.Queue(readQuery)
+	batch.Queue(listQuery)
+	if err := batch.Send(ctx); err != nil {
+		t.Fatalf("\nError from batched Read and List for %s\n%s\n", "member", err)
 	}
 
-	err = member.Delete(pk)
+	batched, err := readQuery.Result()
 	if err != nil {
-		t.Fatalf("\nError from Delete row for %s\n%s\n", "member", err)
+		t.Fatalf("\nError from batched Read row for %s\n%s\n", "member", err)
+	}
+	if !pgsql.Equal(batched.Id, pk.Id) {
+		t.Errorf("Failed equivalency for batched.%s", "Id")
+	}
+
+	if list, err := listQuery.Result(); err != nil || len(list) != 1 {
+		t.Errorf("\nBatched List for %s returned %d rows, expected 1\n%v\n", "member", len(list), err)
+	}
+
+	pkFilter := pgsql.Filter{Expr: "id = ?", Args: []interface{}{pk.Id}}
+	iterated := 0
+	err = store.Iterate(ctx, pkFilter, func(item Member) error {
+		iterated++
+		return nil
+	}, pgsql.WithCursor(1))
+	if err != nil || iterated != 1 {
+		t.Errorf("\nIterate with a cursor over %s called fn %d times, expected 1\n%v\n", "member", iterated, err)
+	}
+
+	for item, err := range store.All(ctx, pkFilter) {
+		if err != nil {
+			t.Fatalf("\nError from All rows for %s\n%s\n", "member", err)
+		}
+		if !pgsql.Equal(item.Id, pk.Id) {
+			t.Errorf("Failed equivalency for iterated.%s", "Id")
+		}
+	}
+
+	var updated *Member
+	var n int64
+	_, err = memberconn.PgSQL.RunInTx(ctx, nil, func(ctx context.Context, tx *pgsql.PgSQL) error {
+		var err error
+		updated, n, err = NewMemberStore(tx).Update(ctx, returnedVal)
+		return err
+	})
+	if err != nil || n != 1 {
+		t.Fatalf("\nError from Update row for %s in a transaction, %d rows affected\n%v\n", "member", n, err)
+	}
+
+	if !pgsql.Equal(updated.Firstname, s.Firstname) {
+		t.Errorf("Failed equivalency for updated.%s", "Firstname")
+	}
+
+	if !pgsql.Equal(updated.Lastname, s.Lastname) {
+		t.Errorf("Failed equivalency for updated.%s", "Lastname")
+	}
+
+	if !pgsql.Equal(updated.Email, s.Email) {
+		t.Errorf("Failed equivalency for updated.%s", "Email")
+	}
+
+	if !pgsql.Equal(updated.Password, s.Password) {
+		t.Errorf("Failed equivalency for updated.%s", "Password")
+	}
+
+	if _, err := store.Patch(ctx, pk, MemberPatch{}); !errors.Is(err, pgsql.ErrEmptyPatch) {
+		t.Errorf("\nPatch setting no column of %s returned %v, expected pgsql.ErrEmptyPatch\n", "member", err)
+	}
+
+	patched, err := store.Patch(ctx, pk, MemberPatch{Firstname: pgsql.Set(updated.Firstname)})
+	if err != nil {
+		t.Fatalf("\nError from Patch row for %s\n%s\n", "member", err)
+	}
+
+	if !pgsql.Equal(patched.Firstname, updated.Firstname) {
+		t.Errorf("Failed equivalency for patched.%s", "Firstname")
+	}
+
+	n, err = store.Delete(ctx, pk)
+	if err != nil || n != 1 {
+		t.Fatalf("\nError from Delete row for %s, %d rows affected\n%v\n", "member", n, err)
+	}
+
+	if _, err = store.Delete(ctx, pk); !errors.Is(err, pgsql.ErrNotFound) {
+		t.Errorf("\nDelete of a deleted row for %s returned %v, expected pgsql.ErrNotFound\n", "member", err)
+	}
+
+	if _, _, err = store.Update(ctx, updated); !errors.Is(err, pgsql.ErrNotFound) {
+		t.Errorf("\nUpdate of a deleted row for %s returned %v, expected pgsql.ErrNotFound\n", "member", err)
+	}
+}
+
+// BenchmarkPublicMemberRead compares Read through the statement cache with Read
+// sending and parsing the statement on every call
+func BenchmarkPublicMemberRead(b *testing.B) {
+	memberSetup(b)
+
+	ctx := context.Background()
+	uncached, err := pgsql.NewPgSQL("database=touch user=touch_dbuser password=verified_touch_user_001 host=192.168.0.107", pgsql.WithStatementCache(false))
+	if err != nil {
+		b.Fatalf("\nPgSQL error during setup: %s\n", err)
+	}
+	defer uncached.Close()
+
+	store := NewMemberStore(memberconn.PgSQL)
+	created, err := store.Create(ctx, struct {
+		Firstname string `db:"firstname"`
+		Lastname  string `db:"lastname"`
+		Email     string `db:"email"`
+		Password  string `db:"password"`
+	}{
+		Firstname: "test 1",
+		Lastname:  "test 2",
+		Email:     "test 3",
+		Password:  "test 4",
+	})
+	if err != nil {
+		b.Fatalf("\nError from Create row for %s\n%s\n", "member", err)
+	}
+
+	pk := &MemberPrimaryKey{Id: created.Id}
+	defer store.Delete(ctx, pk)
+
+	read := func(pg *pgsql.PgSQL) func(b *testing.B) {
+		store := NewMemberStore(pg)
+		return func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := store.Read(ctx, pk); err != nil {
+					b.Fatalf("\nError from Read row for %s\n%s\n", "member", err)
+				}
+			}
+		}
 	}
 
+	b.Run("prepared", read(memberconn.PgSQL))
+	b.Run("unprepared", read(uncached))
 }
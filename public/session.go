@@ -1,106 +1,183 @@
 package public
 
 import (
+	"context"
 	"fmt"
+	"github.com/google/uuid"
+	"iter"
 	"pggen/pgsql"
-	"reflect"
 	"time"
 )
 
 // Session models the table public.session
 type Session struct {
-	pgSQL   *pgsql.PgSQL
-	Id      string
-	Created time.Time
-	Updated time.Time
-	Store   pgsql.JSONStr
+	Id      uuid.UUID       `db:"id"`
+	Created time.Time       `db:"created"`
+	Updated time.Time       `db:"updated"`
+	Store   pgsql.JSON[any] `db:"store"`
 }
 
 // SessionPrimaryKey models the primary key for the table public.session
 type SessionPrimaryKey struct {
-	Id string
+	Id uuid.UUID
 }
 
-// NewSession instantiates and returns a Session struct
-func NewSession(pgSQL *pgsql.PgSQL) *Session {
-	s := new(Session)
-	s.pgSQL = pgSQL
+// SessionPatch holds the columns of a partial update of the table public.session,
+// only the fields that are set are updated
+type SessionPatch struct {
+	Created pgsql.Optional[time.Time]
+	Updated pgsql.Optional[time.Time]
+	Store   pgsql.Optional[pgsql.JSON[any]]
+}
+
+// SessionStore reads and writes the rows of the table public.session. It holds no row
+// state, every method returns new Session values, so a store is safe for concurrent use
+type SessionStore struct {
+	pgSQL *pgsql.PgSQL
+}
 
-	return s
+// NewSessionStore instantiates and returns a SessionStore
+func NewSessionStore(pgSQL *pgsql.PgSQL) *SessionStore {
+	return &SessionStore{pgSQL: pgSQL}
 }
 
 // Create inserts a Session record into the public.session table
 // using the values of the interface argument as an initializer
-func (session *Session) Create(s interface{}) (*SessionPrimaryKey, error) {
-	if err := session.pgSQL.Db.Ping(); err != nil {
-		return nil, err
+// and returns the inserted row, with the values postgres assigned.
+// Constraint violations are returned as the typed errors of pgsql.Classify
+func (store *SessionStore) Create(ctx context.Context, s interface{}) (*Session, error) {
+	insertStmt := fmt.Sprintf("%s %s", pgsql.InsertClause(s, "session"), "returning id, created, updated, store")
+
+	row := store.pgSQL.Prepared().QueryRowContext(ctx, insertStmt, pgsql.FieldValues(s)...)
+	created := new(Session)
+	if err := row.Scan(&created.Id, &created.Created, &created.Updated, &created.Store); err != nil {
+		return nil, pgsql.Classify(err, "public.session")
 	}
 
-	insertStmt := fmt.Sprintf("%s %s", pgsql.InsertClause(s, "session"), "returning id")
-
-	v := reflect.ValueOf(s)
-	n := v.NumField()
-	ifs := make([]interface{}, n)
-
-	for i := 0; i < n; i++ {
-		switch v.Field(i).Kind() {
-		case reflect.Int8, reflect.Int, reflect.Int64:
-			ifs[i] = v.Field(i).Int()
-		case reflect.Uint8, reflect.Uint, reflect.Uint64:
-			ifs[i] = v.Field(i).Uint()
-		case reflect.Float64, reflect.Float32:
-			ifs[i] = v.Field(i).Float()
-		case reflect.Bool:
-			ifs[i] = v.Field(i).Bool()
-		case reflect.String:
-			ifs[i] = v.Field(i).String()
-		case reflect.Struct:
-			ifs[i] = v.Field(i).MethodByName("Format").Call([]reflect.Value{reflect.ValueOf(time.RFC3339)})[0].String()
-		}
-	}
+	return created, nil
+}
 
-	row := session.pgSQL.Db.QueryRow(insertStmt, ifs...)
-	pk := new(SessionPrimaryKey)
-	err := row.Scan(&pk.Id)
+// Read selects the  public.session row keyed by  SessionPrimaryKey and returns a *Session, error tuple
+// A *pgsql.NotFoundError is returned when no row matched
+func (store *SessionStore) Read(ctx context.Context, pk *SessionPrimaryKey) (*Session, error) {
+	return store.ReadQuery(pk).Run(ctx, store.pgSQL)
+}
 
-	return pk, err
+// ReadQuery returns the query run by Read, to queue on a pgsql.Batch with other queries
+func (store *SessionStore) ReadQuery(pk *SessionPrimaryKey) *pgsql.Query[*Session] {
+	selectStmt := "select id, created, updated, store from session where id = $1"
+	args := []interface{}{pk.Id}
+
+	return pgsql.RowQuery("public.session", selectStmt, args, scanSession)
 }
 
-// Read selects the  public.session row keyed by  SessionPrimaryKey and returns a *Session, error tuple
-func (session *Session) Read(pk *SessionPrimaryKey) (*Session, error) {
-	if err := session.pgSQL.Db.Ping(); err != nil {
+// scanSession scans a row of all the columns of public.session
+func scanSession(row pgsql.Row) (*Session, error) {
+	item := new(Session)
+	if err := row.Scan(&item.Id, &item.Created, &item.Updated, &item.Store); err != nil {
 		return nil, err
 	}
 
-	selectStmt := "select id, created, updated, store from session where id = $1"
+	return item, nil
+}
 
-	row := session.pgSQL.Db.QueryRow(selectStmt, pk.Id)
+// Update updates the row of the public.session table represented by the Session argument
+// and returns the updated row and the number of rows affected. A *pgsql.NotFoundError is returned when no row matched
+func (store *SessionStore) Update(ctx context.Context, s *Session) (*Session, int64, error) {
+	updateStmt := "update session set created = $1, updated = $2, store = $3 where id = $4 " +
+		"returning id, created, updated, store"
+	rows, err := store.pgSQL.Prepared().QueryContext(ctx, updateStmt, s.Created, s.Updated, s.Store, s.Id)
+	if err != nil {
+		return nil, 0, pgsql.Classify(err, "public.session")
+	}
+	defer rows.Close()
+
+	// each updated row is returned, their number is the number of rows affected
+	updated := new(Session)
+	var n int64
+	for rows.Next() {
+		if err := rows.Scan(&updated.Id, &updated.Created, &updated.Updated, &updated.Store); err != nil {
+			return nil, 0, pgsql.Classify(err, "public.session")
+		}
+		n++
+	}
 
-	err := row.Scan(&session.Id, &session.Created, &session.Updated, &session.Store)
+	if err := rows.Err(); err != nil {
+		return nil, 0, pgsql.Classify(err, "public.session")
+	}
 
-	return session, err
+	if n == 0 {
+		return nil, 0, &pgsql.NotFoundError{Table: "public.session"}
+	}
+
+	return updated, n, nil
 }
 
-// Update upates the row of the public.session table represented by the Session argument
-func (session *Session) Update(s *Session) error {
-	if err := session.pgSQL.Db.Ping(); err != nil {
-		return err
+// Patch updates only the columns set in patch of the public.session row keyed by pk, leaving
+// concurrent updates of other columns in place, and returns the updated row. A patch setting no column
+// returns pgsql.ErrEmptyPatch, a *pgsql.NotFoundError is returned when no row matched
+func (store *SessionStore) Patch(ctx context.Context, pk *SessionPrimaryKey, patch SessionPatch) (*Session, error) {
+	var set pgsql.Assignments
+	if patch.Created.Set {
+		set.Add("created", patch.Created.Value)
+	}
+	if patch.Updated.Set {
+		set.Add("updated", patch.Updated.Value)
+	}
+	if patch.Store.Set {
+		set.Add("store", patch.Store.Value)
+	}
+	if set.Len() == 0 {
+		return nil, pgsql.ErrEmptyPatch
 	}
 
-	updateStmt := "update session set created = $1, updated = $2, store = $3 where id = $1"
-	_, err := session.pgSQL.Db.Exec(updateStmt, s.Created, s.Updated, s.Store, s.Id)
+	where := pgsql.Filter{Expr: "id = ?", Args: []interface{}{pk.Id}}
+	patchStmt := "update session set " + set.Clause() + where.Where(set.Len()+1) + " returning id, created, updated, store"
 
-	return err
+	return pgsql.DynamicRowQuery("public.session", patchStmt, append(set.Args(), where.Args...), scanSession).Run(ctx, store.pgSQL)
 }
 
-// Delete removes the Session row from the database
-func (session *Session) Delete(pk *SessionPrimaryKey) error {
-	if err := session.pgSQL.Db.Ping(); err != nil {
-		return err
+// Delete removes the Session row from the database and returns the number of rows affected.
+// A *pgsql.NotFoundError is returned when no row matched
+func (store *SessionStore) Delete(ctx context.Context, pk *SessionPrimaryKey) (int64, error) {
+	deleteStmt := "delete from session  where id = $1"
+	result, err := store.pgSQL.Prepared().ExecContext(ctx, deleteStmt, pk.Id)
+	if err != nil {
+		return 0, pgsql.Classify(err, "public.session")
 	}
 
-	deleteStmt := "delete from session  where id = $1"
-	_, err := session.pgSQL.Db.Exec(deleteStmt, pk.Id)
+	n, err := result.RowsAffected()
+	if err == nil && n == 0 {
+		err = &pgsql.NotFoundError{Table: "public.session"}
+	}
+
+	return n, err
+}
+
+// List selects the public.session rows matching filter, ordered and paged by opts
+func (store *SessionStore) List(ctx context.Context, filter pgsql.Filter, opts pgsql.ListOptions) ([]*Session, error) {
+	return store.ListQuery(filter, opts).Run(ctx, store.pgSQL)
+}
+
+// ListQuery returns the query run by List, to queue on a pgsql.Batch with other queries
+func (store *SessionStore) ListQuery(filter pgsql.Filter, opts pgsql.ListOptions) *pgsql.Query[[]*Session] {
+	selectStmt := "select id, created, updated, store from session" + filter.Where(1) + opts.Clause(len(filter.Args)+1)
+
+	return pgsql.RowsQuery("public.session", selectStmt, opts.Args(filter.Args), scanSession)
+}
+
+// Iterate calls fn with each public.session row matching filter as it is read, without loading them
+// all, and stops at the first error fn returns. Give pgsql.WithCursor to read the rows through a server side cursor
+func (store *SessionStore) Iterate(ctx context.Context, filter pgsql.Filter, fn func(Session) error, opts ...pgsql.IterOption) error {
+	selectStmt := "select id, created, updated, store from session" + filter.Where(1)
+
+	return pgsql.Iterate(ctx, store.pgSQL, "public.session", selectStmt, filter.Args, scanSession, fn, opts...)
+}
+
+// All returns an iterator over the public.session rows matching filter, read as by Iterate.
+// An error ends the iteration
+func (store *SessionStore) All(ctx context.Context, filter pgsql.Filter, opts ...pgsql.IterOption) iter.Seq2[Session, error] {
+	selectStmt := "select id, created, updated, store from session" + filter.Where(1)
 
-	return err
+	return pgsql.All(ctx, store.pgSQL, "public.session", selectStmt, filter.Args, scanSession, opts...)
 }
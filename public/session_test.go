@@ -1,10 +1,12 @@
 package public_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	"pggen/pgsql"
 	. "pggen/public"
-	"reflect"
 	"testing"
 	"time"
 )
@@ -15,7 +17,7 @@ type sessionDbConnection struct {
 
 var sessionconn sessionDbConnection
 
-func sessionSetup(t *testing.T) {
+func sessionSetup(t testing.TB) {
 	fmt.Println("Running setup")
 	if sessionconn.PgSQL == nil {
 		connectionStr := "database=touch user=touch_dbuser password=verified_touch_user_001 host=192.168.0.107"
@@ -31,38 +33,219 @@ func sessionSetup(t *testing.T) {
 func TestPublicSession(t *testing.T) {
 	sessionSetup(t)
 
-	session := NewSession(sessionconn.PgSQL)
+	ctx := context.Background()
+	store := NewSessionStore(sessionconn.PgSQL)
 
 	s := struct {
-		Id      string
-		Created time.Time
-		Updated time.Time
-		Store   pgsql.JSONStr
+		Id      uuid.UUID       `db:"id"`
+		Created time.Time       `db:"created"`
+		Updated time.Time       `db:"updated"`
+		Store   pgsql.JSON[any] `db:"store"`
 	}{
-		Id:      "urn:uuid:e86a949b-020e-4170-a705-aeddb0b242fc",
+		Id:      uuid.MustParse("9080c12e-5c7d-46cd-b838-702dc0c4dfe7"),
 		Created: pgsql.TimeOnly(time.Parse(time.RFC3339, "2019-08-27T10:21:19-07:00")),
 		Updated: pgsql.TimeOnly(time.Parse(time.RFC3339, "2019-08-27T10:21:19-07:00")),
-		Store:   `{"ID":123,"Name":"Hello, World"}`,
+		Store:   pgsql.MustJSON[any](`{"ID": 126, "Name": "Hello, World"}`),
 	}
 
-	pk, err := session.Create(s)
+	created, err := store.Create(ctx, s)
 
 	if err != nil {
 		t.Fatalf("\nError from Create row for %s\n%s\n", "session", err)
 	}
 
-	returnedVal, err := session.Read(pk)
+	pk := &SessionPrimaryKey{Id: created.Id}
+
+	returnedVal, err := store.Read(ctx, pk)
 	if err != nil {
 		t.Fatalf("\nError from Read row for %s\n%s\n", "session", err)
 	}
 
-	if !reflect.DeepEqual(returnedVal, session) {
-		t.Errorf("Failed equivalency for returnedVal and %s", "session")
+	if !pgsql.Equal(created.Id, s.Id) {
+		t.Errorf("Failed equivalency for created.%s", "Id")
+	}
+
+	if !pgsql.Equal(returnedVal.Id, s.Id) {
+		t.Errorf("Failed equivalency for returnedVal.%s", "Id")
+	}
+
+	if !pgsql.Equal(created.Created, s.Created) {
+		t.Errorf("Failed equivalency for created.%s", "Created")
+	}
+
+	if !pgsql.Equal(returnedVal.Created, s.Created) {
+		t.Errorf("Failed equivalency for returnedVal.%s", "Created")
+	}
+
+	if !pgsql.Equal(created.Updated, s.Updated) {
+		t.Errorf("Failed equivalency for created.%s", "Updated")
+	}
+
+	if !pgsql.Equal(returnedVal.Updated, s.Updated) {
+		t.Errorf("Failed equivalency for returnedVal.%s", "Updated")
+	}
+
+	if !pgsql.Equal(created.Store, s.Store) {
+		t.Errorf("Failed equivalency for created.%s", "Store")
+	}
+
+	if !pgsql.Equal(returnedVal.Store, s.Store) {
+		t.Errorf("Failed equivalency for returnedVal.%s", "Store")
+	}
+
+	reads := make(chan *Session)
+	for i := 0; i < 4; i++ {
+		go func() {
+			read, err := store.Read(ctx, pk)
+			if err != nil {
+				t.Errorf("\nError from concurrent Read row for %s\n%s\n", "session", err)
+			}
+			reads <- read
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		read := <-reads
+		if read != nil && !pgsql.Equal(read.Id, pk.Id) {
+			t.Errorf("Failed equivalency for concurrently read.%s", "Id")
+		}
 	}
 
-	err = session.Delete(pk)
+	batch := sessionconn.PgSQL.NewBatch()
+	readQuery := store.ReadQuery(pk)
+	listQuery := store.ListQuery(pgsql.Filter{}, pgsql.ListOptions{Limit: 1})
+	batch.Queue(readQuery)
+	batch.Queue(listQuery)
+	if err := batch.Send(ctx); err != nil {
+		t.Fatalf("\nError from batched Read and List for %s\n%s\n", "session", err)
+	}
+
+	batched, err := readQuery.Result()
 	if err != nil {
-		t.Fatalf("\nError from Delete row for %s\n%s\n", "session", err)
+		t.Fatalf("\nError from batched Read row for %s\n%s\n", "session", err)
+	}
+	if !pgsql.Equal(batched.Id, pk.Id) {
+		t.Errorf("Failed equivalency for batched.%s", "Id")
+	}
+
+	if list, err := listQuery.Result(); err != nil || len(list) != 1 {
+		t.Errorf("\nBatched List for %s returned %d rows, expected 1\n%v\n", "session", len(list), err)
+	}
+
+	pkFilter := pgsql.Filter{Expr: "id = ?", Args: []interface{}{pk.Id}}
+	iterated := 0
+	err = store.Iterate(ctx, pkFilter, func(item Session) error {
+		iterated++
+		return nil
+	}, pgsql.WithCursor(1))
+	if err != nil || iterated != 1 {
+		t.Errorf("\nIterate with a cursor over %s called fn %d times, expected 1\n%v\n", "session", iterated, err)
+	}
+
+	for item, err := range store.All(ctx, pkFilter) {
+		if err != nil {
+			t.Fatalf("\nError from All rows for %s\n%s\n", "session", err)
+		}
+		if !pgsql.Equal(item.Id, pk.Id) {
+			t.Errorf("Failed equivalency for iterated.%s", "Id")
+		}
+	}
+
+	var updated *Session
+	var n int64
+	_, err = sessionconn.PgSQL.RunInTx(ctx, nil, func(ctx context.Context, tx *pgsql.PgSQL) error {
+		var err error
+		updated, n, err = NewSessionStore(tx).Update(ctx, returnedVal)
+		return err
+	})
+	if err != nil || n != 1 {
+		t.Fatalf("\nError from Update row for %s in a transaction, %d rows affected\n%v\n", "session", n, err)
+	}
+
+	if !pgsql.Equal(updated.Id, s.Id) {
+		t.Errorf("Failed equivalency for updated.%s", "Id")
+	}
+
+	if !pgsql.Equal(updated.Created, s.Created) {
+		t.Errorf("Failed equivalency for updated.%s", "Created")
+	}
+
+	if !pgsql.Equal(updated.Updated, s.Updated) {
+		t.Errorf("Failed equivalency for updated.%s", "Updated")
+	}
+
+	if !pgsql.Equal(updated.Store, s.Store) {
+		t.Errorf("Failed equivalency for updated.%s", "Store")
+	}
+
+	if _, err := store.Patch(ctx, pk, SessionPatch{}); !errors.Is(err, pgsql.ErrEmptyPatch) {
+		t.Errorf("\nPatch setting no column of %s returned %v, expected pgsql.ErrEmptyPatch\n", "session", err)
+	}
+
+	patched, err := store.Patch(ctx, pk, SessionPatch{Created: pgsql.Set(updated.Created)})
+	if err != nil {
+		t.Fatalf("\nError from Patch row for %s\n%s\n", "session", err)
+	}
+
+	if !pgsql.Equal(patched.Created, updated.Created) {
+		t.Errorf("Failed equivalency for patched.%s", "Created")
+	}
+
+	n, err = store.Delete(ctx, pk)
+	if err != nil || n != 1 {
+		t.Fatalf("\nError from Delete row for %s, %d rows affected\n%v\n", "session", n, err)
+	}
+
+	if _, err = store.Delete(ctx, pk); !errors.Is(err, pgsql.ErrNotFound) {
+		t.Errorf("\nDelete of a deleted row for %s returned %v, expected pgsql.ErrNotFound\n", "session", err)
+	}
+
+	if _, _, err = store.Update(ctx, updated); !errors.Is(err, pgsql.ErrNotFound) {
+		t.Errorf("\nUpdate of a deleted row for %s returned %v, expected pgsql.ErrNotFound\n", "session", err)
+	}
+}
+
+// BenchmarkPublicSessionRead compares Read through the statement cache with Read
+// sending and parsing the statement on every call
+func BenchmarkPublicSessionRead(b *testing.B) {
+	sessionSetup(b)
+
+	ctx := context.Background()
+	uncached, err := pgsql.NewPgSQL("database=touch user=touch_dbuser password=verified_touch_user_001 host=192.168.0.107", pgsql.WithStatementCache(false))
+	if err != nil {
+		b.Fatalf("\nPgSQL error during setup: %s\n", err)
+	}
+	defer uncached.Close()
+
+	store := NewSessionStore(sessionconn.PgSQL)
+	created, err := store.Create(ctx, struct {
+		Id      uuid.UUID       `db:"id"`
+		Created time.Time       `db:"created"`
+		Updated time.Time       `db:"updated"`
+		Store   pgsql.JSON[any] `db:"store"`
+	}{
+		Id:      uuid.MustParse("af0b58a4-085a-4cd3-b213-666d2eba727b"),
+		Created: pgsql.TimeOnly(time.Parse(time.RFC3339, "2026-10-19T06:55:43Z")),
+		Updated: pgsql.TimeOnly(time.Parse(time.RFC3339, "2026-10-19T06:55:43Z")),
+		Store:   pgsql.MustJSON[any](`{"ID": 126, "Name": "Hello, World"}`),
+	})
+	if err != nil {
+		b.Fatalf("\nError from Create row for %s\n%s\n", "session", err)
+	}
+
+	pk := &SessionPrimaryKey{Id: created.Id}
+	defer store.Delete(ctx, pk)
+
+	read := func(pg *pgsql.PgSQL) func(b *testing.B) {
+		store := NewSessionStore(pg)
+		return func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := store.Read(ctx, pk); err != nil {
+					b.Fatalf("\nError from Read row for %s\n%s\n", "session", err)
+				}
+			}
+		}
 	}
 
+	b.Run("prepared", read(sessionconn.PgSQL))
+	b.Run("unprepared", read(uncached))
 }
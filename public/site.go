@@ -1,18 +1,19 @@
 package public
 
 import (
+	"context"
 	"fmt"
+	"iter"
 	"pggen/pgsql"
-	"reflect"
-	"time"
 )
 
 // Site models the table public.site
 type Site struct {
-	pgSQL    *pgsql.PgSQL
-	Domain   string
-	Memberid int
-	Role     string
+	Domain   string `db:"domain"`
+	Memberid int    `db:"memberid"`
+	Role     string `db:"role"`
+	// Xmin is the id of the transaction that last wrote the row, its version
+	Xmin uint32 `db:"xmin" pggen:"readonly"`
 }
 
 // SitePrimaryKey models the primary key for the table public.site
@@ -21,86 +22,161 @@ type SitePrimaryKey struct {
 	Memberid int
 }
 
-// NewSite instantiates and returns a Site struct
-func NewSite(pgSQL *pgsql.PgSQL) *Site {
-	s := new(Site)
-	s.pgSQL = pgSQL
+// SitePatch holds the columns of a partial update of the table public.site,
+// only the fields that are set are updated
+type SitePatch struct {
+	Role pgsql.Optional[string]
+}
+
+// SiteStore reads and writes the rows of the table public.site. It holds no row
+// state, every method returns new Site values, so a store is safe for concurrent use
+type SiteStore struct {
+	pgSQL *pgsql.PgSQL
+}
 
-	return s
+// NewSiteStore instantiates and returns a SiteStore
+func NewSiteStore(pgSQL *pgsql.PgSQL) *SiteStore {
+	return &SiteStore{pgSQL: pgSQL}
 }
 
 // Create inserts a Site record into the public.site table
 // using the values of the interface argument as an initializer
-func (site *Site) Create(s interface{}) (*SitePrimaryKey, error) {
-	if err := site.pgSQL.Db.Ping(); err != nil {
-		return nil, err
+// and returns the inserted row, with the values postgres assigned.
+// Constraint violations are returned as the typed errors of pgsql.Classify
+func (store *SiteStore) Create(ctx context.Context, s interface{}) (*Site, error) {
+	insertStmt := fmt.Sprintf("%s %s", pgsql.InsertClause(s, "site"), "returning domain, memberid, role, xmin")
+
+	row := store.pgSQL.Prepared().QueryRowContext(ctx, insertStmt, pgsql.FieldValues(s)...)
+	created := new(Site)
+	if err := row.Scan(&created.Domain, &created.Memberid, &created.Role, &created.Xmin); err != nil {
+		return nil, pgsql.Classify(err, "public.site")
 	}
 
-	insertStmt := fmt.Sprintf("%s %s", pgsql.InsertClause(s, "site"), "returning domain, memberid")
-
-	v := reflect.ValueOf(s)
-	n := v.NumField()
-	ifs := make([]interface{}, n)
-
-	for i := 0; i < n; i++ {
-		switch v.Field(i).Kind() {
-		case reflect.Int8, reflect.Int, reflect.Int64:
-			ifs[i] = v.Field(i).Int()
-		case reflect.Uint8, reflect.Uint, reflect.Uint64:
-			ifs[i] = v.Field(i).Uint()
-		case reflect.Float64, reflect.Float32:
-			ifs[i] = v.Field(i).Float()
-		case reflect.Bool:
-			ifs[i] = v.Field(i).Bool()
-		case reflect.String:
-			ifs[i] = v.Field(i).String()
-		case reflect.Struct:
-			ifs[i] = v.Field(i).MethodByName("Format").Call([]reflect.Value{reflect.ValueOf(time.RFC3339)})[0].String()
-		}
-	}
+	return created, nil
+}
 
-	row := site.pgSQL.Db.QueryRow(insertStmt, ifs...)
-	pk := new(SitePrimaryKey)
-	err := row.Scan(&pk.Domain, &pk.Memberid)
+// Read selects the  public.site row keyed by  SitePrimaryKey and returns a *Site, error tuple
+// A *pgsql.NotFoundError is returned when no row matched
+func (store *SiteStore) Read(ctx context.Context, pk *SitePrimaryKey) (*Site, error) {
+	return store.ReadQuery(pk).Run(ctx, store.pgSQL)
+}
+
+// ReadQuery returns the query run by Read, to queue on a pgsql.Batch with other queries
+func (store *SiteStore) ReadQuery(pk *SitePrimaryKey) *pgsql.Query[*Site] {
+	selectStmt := "select domain, memberid, role, xmin from site where domain = $1 and memberid = $2"
+	args := []interface{}{pk.Domain, pk.Memberid}
 
-	return pk, err
+	return pgsql.RowQuery("public.site", selectStmt, args, scanSite)
 }
 
-// Read selects the  public.site row keyed by  SitePrimaryKey and returns a *Site, error tuple
-func (site *Site) Read(pk *SitePrimaryKey) (*Site, error) {
-	if err := site.pgSQL.Db.Ping(); err != nil {
+// scanSite scans a row of all the columns of public.site
+func scanSite(row pgsql.Row) (*Site, error) {
+	item := new(Site)
+	if err := row.Scan(&item.Domain, &item.Memberid, &item.Role, &item.Xmin); err != nil {
 		return nil, err
 	}
 
-	selectStmt := "select domain, memberid, role from site where domain = $1 and memberid = $2"
+	return item, nil
+}
 
-	row := site.pgSQL.Db.QueryRow(selectStmt, pk.Domain, pk.Memberid)
+// Update updates the row of the public.site table represented by the Site argument
+// and returns the updated row and the number of rows affected. The row must still have the xmin of s,
+// which postgres advances, otherwise a *pgsql.StaleObjectError is returned
+func (store *SiteStore) Update(ctx context.Context, s *Site) (*Site, int64, error) {
+	updateStmt := "update site set role = $1 where domain = $2 and memberid = $3 and xmin = $4 " +
+		"returning domain, memberid, role, xmin"
+	rows, err := store.pgSQL.Prepared().QueryContext(ctx, updateStmt, s.Role, s.Domain, s.Memberid, s.Xmin)
+	if err != nil {
+		return nil, 0, pgsql.Classify(err, "public.site")
+	}
+	defer rows.Close()
+
+	// each updated row is returned, their number is the number of rows affected
+	updated := new(Site)
+	var n int64
+	for rows.Next() {
+		if err := rows.Scan(&updated.Domain, &updated.Memberid, &updated.Role, &updated.Xmin); err != nil {
+			return nil, 0, pgsql.Classify(err, "public.site")
+		}
+		n++
+	}
 
-	err := row.Scan(&site.Domain, &site.Memberid, &site.Role)
+	if err := rows.Err(); err != nil {
+		return nil, 0, pgsql.Classify(err, "public.site")
+	}
 
-	return site, err
+	if n == 0 {
+		return nil, 0, &pgsql.StaleObjectError{Table: "public.site"}
+	}
+
+	return updated, n, nil
 }
 
-// Update upates the row of the public.site table represented by the Site argument
-func (site *Site) Update(s *Site) error {
-	if err := site.pgSQL.Db.Ping(); err != nil {
-		return err
+// Patch updates only the columns set in patch of the public.site row keyed by pk, leaving
+// concurrent updates of other columns in place, and returns the updated row. A patch setting no column
+// returns pgsql.ErrEmptyPatch. The row must still have the xmin given,
+// otherwise a *pgsql.StaleObjectError is returned
+func (store *SiteStore) Patch(ctx context.Context, pk *SitePrimaryKey, version uint32, patch SitePatch) (*Site, error) {
+	var set pgsql.Assignments
+	if patch.Role.Set {
+		set.Add("role", patch.Role.Value)
+	}
+	if set.Len() == 0 {
+		return nil, pgsql.ErrEmptyPatch
 	}
 
-	updateStmt := "update site set role = $1 where domain = $1 and memberid = $2"
-	_, err := site.pgSQL.Db.Exec(updateStmt, s.Role, s.Domain, s.Memberid)
+	where := pgsql.Filter{Expr: "domain = ? and memberid = ? and xmin = ?", Args: []interface{}{pk.Domain, pk.Memberid, version}}
+	patchStmt := "update site set " + set.Clause() + where.Where(set.Len()+1) + " returning domain, memberid, role, xmin"
+
+	patched, err := pgsql.DynamicRowQuery("public.site", patchStmt, append(set.Args(), where.Args...), scanSite).Run(ctx, store.pgSQL)
+	if err != nil {
+		return nil, pgsql.Stale(err, "public.site")
+	}
 
-	return err
+	return patched, nil
 }
 
-// Delete removes the Site row from the database
-func (site *Site) Delete(pk *SitePrimaryKey) error {
-	if err := site.pgSQL.Db.Ping(); err != nil {
-		return err
+// Delete removes the Site row from the database and returns the number of rows affected.
+// The row must still have the xmin given, otherwise a *pgsql.StaleObjectError is returned
+func (store *SiteStore) Delete(ctx context.Context, pk *SitePrimaryKey, version uint32) (int64, error) {
+	deleteStmt := "delete from site  where domain = $1 and memberid = $2 and xmin = $3"
+	result, err := store.pgSQL.Prepared().ExecContext(ctx, deleteStmt, pk.Domain, pk.Memberid, version)
+	if err != nil {
+		return 0, pgsql.Classify(err, "public.site")
 	}
 
-	deleteStmt := "delete from site  where domain = $1 and memberid = $2"
-	_, err := site.pgSQL.Db.Exec(deleteStmt, pk.Domain, pk.Memberid)
+	n, err := result.RowsAffected()
+	if err == nil && n == 0 {
+		err = &pgsql.StaleObjectError{Table: "public.site"}
+	}
+
+	return n, err
+}
+
+// List selects the public.site rows matching filter, ordered and paged by opts
+func (store *SiteStore) List(ctx context.Context, filter pgsql.Filter, opts pgsql.ListOptions) ([]*Site, error) {
+	return store.ListQuery(filter, opts).Run(ctx, store.pgSQL)
+}
+
+// ListQuery returns the query run by List, to queue on a pgsql.Batch with other queries
+func (store *SiteStore) ListQuery(filter pgsql.Filter, opts pgsql.ListOptions) *pgsql.Query[[]*Site] {
+	selectStmt := "select domain, memberid, role, xmin from site" + filter.Where(1) + opts.Clause(len(filter.Args)+1)
+
+	return pgsql.RowsQuery("public.site", selectStmt, opts.Args(filter.Args), scanSite)
+}
+
+// Iterate calls fn with each public.site row matching filter as it is read, without loading them
+// all, and stops at the first error fn returns. Give pgsql.WithCursor to read the rows through a server side cursor
+func (store *SiteStore) Iterate(ctx context.Context, filter pgsql.Filter, fn func(Site) error, opts ...pgsql.IterOption) error {
+	selectStmt := "select domain, memberid, role, xmin from site" + filter.Where(1)
+
+	return pgsql.Iterate(ctx, store.pgSQL, "public.site", selectStmt, filter.Args, scanSite, fn, opts...)
+}
+
+// All returns an iterator over the public.site rows matching filter, read as by Iterate.
+// An error ends the iteration
+func (store *SiteStore) All(ctx context.Context, filter pgsql.Filter, opts ...pgsql.IterOption) iter.Seq2[Site, error] {
+	selectStmt := "select domain, memberid, role, xmin from site" + filter.Where(1)
 
-	return err
+	return pgsql.All(ctx, store.pgSQL, "public.site", selectStmt, filter.Args, scanSite, opts...)
 }
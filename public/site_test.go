@@ -1,10 +1,11 @@
 package public_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"pggen/pgsql"
 	. "pggen/public"
-	"reflect"
 	"testing"
 )
 
@@ -14,7 +15,7 @@ type siteDbConnection struct {
 
 var siteconn siteDbConnection
 
-func siteSetup(t *testing.T) {
+func siteSetup(t testing.TB) {
 	fmt.Println("Running setup")
 	if siteconn.PgSQL == nil {
 		connectionStr := "database=touch user=touch_dbuser password=verified_touch_user_001 host=192.168.0.107"
@@ -30,36 +31,229 @@ func siteSetup(t *testing.T) {
 func TestPublicSite(t *testing.T) {
 	siteSetup(t)
 
-	site := NewSite(siteconn.PgSQL)
+	ctx := context.Background()
+	store := NewSiteStore(siteconn.PgSQL)
 
 	s := struct {
-		Domain   string
-		Memberid int
-		Role     string
+		Domain   string `db:"domain"`
+		Memberid int    `db:"memberid"`
+		Role     string `db:"role"`
 	}{
 		Domain:   "urn:uuid:42c41dce-3318-4aa4-a125-3dd06f961bfe",
 		Memberid: 1,
 		Role:     "test 2",
 	}
 
-	pk, err := site.Create(s)
+	created, err := store.Create(ctx, s)
 
 	if err != nil {
 		t.Fatalf("\nError from Create row for %s\n%s\n", "site", err)
 	}
 
-	returnedVal, err := site.Read(pk)
+	pk := &SitePrimaryKey{Domain: created.Domain, Memberid: created.Memberid}
+
+	returnedVal, err := store.Read(ctx, pk)
+	if err != nil {
+		t.Fatalf("\nError from Read row for %s\n%s\n", "site", err)
+	}
+
+	if !pgsql.Equal(created.Domain, s.Domain) {
+		t.Errorf("Failed equivalency for created.%s", "Domain")
+	}
+
+	if !pgsql.Equal(returnedVal.Domain, s.Domain) {
+		t.Errorf("Failed equivalency for returnedVal.%s", "Domain")
+	}
+
+	if !pgsql.Equal(created.Memberid, s.Memberid) {
+		t.Errorf("Failed equivalency for created.%s", "Memberid")
+	}
+
+	if !pgsql.Equal(returnedVal.Memberid, s.Memberid) {
+		t.Errorf("Failed equivalency for returnedVal.%s", "Memberid")
+	}
+
+	if !pgsql.Equal(created.Role, s.Role) {
+		t.Errorf("Failed equivalency for created.%s", "Role")
+	}
+
+	if !pgsql.Equal(returnedVal.Role, s.Role) {
+		t.Errorf("Failed equivalency for returnedVal.%s", "Role")
+	}
+
+	reads := make(chan *Site)
+	for i := 0; i < 4; i++ {
+		go func() {
+			read, err := store.Read(ctx, pk)
+			if err != nil {
+				t.Errorf("\nError from concurrent Read row for %s\n%s\n", "site", err)
+			}
+			reads <- read
+		}()
+	}
+	for i := 0; i < 4; i++ {
+		read := <-reads
+		if read != nil && !pgsql.Equal(read.Domain, pk.Domain) {
+			t.Errorf("Failed equivalency for concurrently read.%s", "Domain")
+		}
+		if read != nil && !pgsql.Equal(read.Memberid, pk.Memberid) {
+			t.Errorf("Failed equivalency for concurrently read.%s", "Memberid")
+		}
+	}
+
+	batch := siteconn.PgSQL.NewBatch()
+	readQuery := store.ReadQuery(pk)
+	listQuery := store.ListQuery(pgsql.Filter{}, pgsql.ListOptions{Limit: 1})
+	batch.Queue(readQuery)
+	batch.Queue(listQuery)
+	if err := batch.Send(ctx); err != nil {
+		t.Fatalf("\nError from batched Read and List for %s\n%s\n", "site", err)
+	}
+
+	batched, err := readQuery.Result()
+	if err != nil {
+		t.Fatalf("\nError from batched Read row for %s\n%s\n", "site", err)
+	}
+	if !pgsql.Equal(batched.Domain, pk.Domain) {
+		t.Errorf("Failed equivalency for batched.%s", "Domain")
+	}
+	if !pgsql.Equal(batched.Memberid, pk.Memberid) {
+		t.Errorf("Failed equivalency for batched.%s", "Memberid")
+	}
+
+	if list, err := listQuery.Result(); err != nil || len(list) != 1 {
+		t.Errorf("\nBatched List for %s returned %d rows, expected 1\n%v\n", "site", len(list), err)
+	}
+
+	pkFilter := pgsql.Filter{Expr: "domain = ? and memberid = ?", Args: []interface{}{pk.Domain, pk.Memberid}}
+	iterated := 0
+	err = store.Iterate(ctx, pkFilter, func(item Site) error {
+		iterated++
+		return nil
+	}, pgsql.WithCursor(1))
+	if err != nil || iterated != 1 {
+		t.Errorf("\nIterate with a cursor over %s called fn %d times, expected 1\n%v\n", "site", iterated, err)
+	}
+
+	for item, err := range store.All(ctx, pkFilter) {
+		if err != nil {
+			t.Fatalf("\nError from All rows for %s\n%s\n", "site", err)
+		}
+		if !pgsql.Equal(item.Domain, pk.Domain) {
+			t.Errorf("Failed equivalency for iterated.%s", "Domain")
+		}
+		if !pgsql.Equal(item.Memberid, pk.Memberid) {
+			t.Errorf("Failed equivalency for iterated.%s", "Memberid")
+		}
+	}
+
+	var updated *Site
+	var n int64
+	_, err = siteconn.PgSQL.RunInTx(ctx, nil, func(ctx context.Context, tx *pgsql.PgSQL) error {
+		var err error
+		updated, n, err = NewSiteStore(tx).Update(ctx, returnedVal)
+		return err
+	})
+	if err != nil || n != 1 {
+		t.Fatalf("\nError from Update row for %s in a transaction, %d rows affected\n%v\n", "site", n, err)
+	}
+
+	if !pgsql.Equal(updated.Domain, s.Domain) {
+		t.Errorf("Failed equivalency for updated.%s", "Domain")
+	}
+
+	if !pgsql.Equal(updated.Memberid, s.Memberid) {
+		t.Errorf("Failed equivalency for updated.%s", "Memberid")
+	}
+
+	if !pgsql.Equal(updated.Role, s.Role) {
+		t.Errorf("Failed equivalency for updated.%s", "Role")
+	}
+
+	if _, _, err := store.Update(ctx, returnedVal); !errors.Is(err, pgsql.ErrStaleObject) {
+		t.Errorf("\nUpdate of a stale row for %s returned %v, expected pgsql.ErrStaleObject\n", "site", err)
+	}
+
+	if _, err := store.Patch(ctx, pk, updated.Xmin, SitePatch{}); !errors.Is(err, pgsql.ErrEmptyPatch) {
+		t.Errorf("\nPatch setting no column of %s returned %v, expected pgsql.ErrEmptyPatch\n", "site", err)
+	}
+
+	if _, err := store.Patch(ctx, pk, returnedVal.Xmin, SitePatch{Role: pgsql.Set(updated.Role)}); !errors.Is(err, pgsql.ErrStaleObject) {
+		t.Errorf("\nPatch of a stale row for %s returned %v, expected pgsql.ErrStaleObject\n", "site", err)
+	}
+
+	patched, err := store.Patch(ctx, pk, updated.Xmin, SitePatch{Role: pgsql.Set(updated.Role)})
+	if err != nil {
+		t.Fatalf("\nError from Patch row for %s\n%s\n", "site", err)
+	}
+
+	if !pgsql.Equal(patched.Role, updated.Role) {
+		t.Errorf("Failed equivalency for patched.%s", "Role")
+	}
+
+	latest, err := store.Read(ctx, pk)
 	if err != nil {
 		t.Fatalf("\nError from Read row for %s\n%s\n", "site", err)
 	}
 
-	if !reflect.DeepEqual(returnedVal, site) {
-		t.Errorf("Failed equivalency for returnedVal and %s", "site")
+	if _, err = store.Delete(ctx, pk, returnedVal.Xmin); !errors.Is(err, pgsql.ErrStaleObject) {
+		t.Errorf("\nDelete of a stale row for %s returned %v, expected pgsql.ErrStaleObject\n", "site", err)
+	}
+
+	n, err = store.Delete(ctx, pk, latest.Xmin)
+	if err != nil || n != 1 {
+		t.Fatalf("\nError from Delete row for %s, %d rows affected\n%v\n", "site", n, err)
+	}
+
+	if _, err = store.Delete(ctx, pk, latest.Xmin); !errors.Is(err, pgsql.ErrStaleObject) {
+		t.Errorf("\nDelete of a deleted row for %s returned %v, expected pgsql.ErrStaleObject\n", "site", err)
 	}
 
-	err = site.Delete(pk)
+	if _, _, err = store.Update(ctx, latest); !errors.Is(err, pgsql.ErrStaleObject) {
+		t.Errorf("\nUpdate of a deleted row for %s returned %v, expected pgsql.ErrStaleObject\n", "site", err)
+	}
+}
+
+// BenchmarkPublicSiteRead compares Read through the statement cache with Read
+// sending and parsing the statement on every call
+func BenchmarkPublicSiteRead(b *testing.B) {
+	siteSetup(b)
+
+	ctx := context.Background()
+	uncached, err := pgsql.NewPgSQL("database=touch user=touch_dbuser password=verified_touch_user_001 host=192.168.0.107", pgsql.WithStatementCache(false))
 	if err != nil {
-		t.Fatalf("\nError from Delete row for %s\n%s\n", "site", err)
+		b.Fatalf("\nPgSQL error during setup: %s\n", err)
+	}
+	defer uncached.Close()
+
+	store := NewSiteStore(siteconn.PgSQL)
+	created, err := store.Create(ctx, struct {
+		Domain   string `db:"domain"`
+		Memberid int    `db:"memberid"`
+		Role     string `db:"role"`
+	}{
+		Domain:   "urn:uuid:ea553099-7e30-48ef-aead-45f561146996",
+		Memberid: 1,
+		Role:     "test 2",
+	})
+	if err != nil {
+		b.Fatalf("\nError from Create row for %s\n%s\n", "site", err)
+	}
+
+	pk := &SitePrimaryKey{Domain: created.Domain, Memberid: created.Memberid}
+	defer store.Delete(ctx, pk, created.Xmin)
+
+	read := func(pg *pgsql.PgSQL) func(b *testing.B) {
+		store := NewSiteStore(pg)
+		return func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := store.Read(ctx, pk); err != nil {
+					b.Fatalf("\nError from Read row for %s\n%s\n", "site", err)
+				}
+			}
+		}
 	}
 
+	b.Run("prepared", read(siteconn.PgSQL))
+	b.Run("unprepared", read(uncached))
 }